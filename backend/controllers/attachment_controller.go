@@ -0,0 +1,353 @@
+package controllers
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// 附件大小限制的默认值，单位MB
+const (
+	defaultAttachmentMaxFileMB = 10
+	defaultAttachmentQuotaMB   = 500
+)
+
+// 正在上传的附件预先占用的配额（字节）。开始上传时在锁内检查并预留配额，写入文件时不持有锁，
+// 附件保存后释放预留，同时进行的上传不会超出总配额，也不会因为某个上传很慢而互相阻塞
+var (
+	attachmentUploadLock sync.Mutex
+	attachmentReserved   int64
+)
+
+// errAttachmentTooLarge 上传的文件超过允许的大小
+var errAttachmentTooLarge = errors.New("attachment too large")
+
+// envMegabytes 读取以MB为单位的环境变量并转换为字节数
+func envMegabytes(name string, defaultMB int64) int64 {
+	mb, err := strconv.ParseInt(os.Getenv(name), 10, 64)
+	if err != nil || mb <= 0 {
+		mb = defaultMB
+	}
+	return mb << 20
+}
+
+// attachmentLimits 获取单个附件和所有附件的大小上限（字节）
+func attachmentLimits() (maxFile int64, quota int64) {
+	return envMegabytes("ATTACHMENT_MAX_FILE_MB", defaultAttachmentMaxFileMB),
+		envMegabytes("ATTACHMENT_QUOTA_MB", defaultAttachmentQuotaMB)
+}
+
+// parseAttachmentParams 解析条目ID和附件ID
+func parseAttachmentParams(c *gin.Context) (int, int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return 0, 0, false
+	}
+	attachmentID, err := strconv.Atoi(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的附件ID"})
+		return 0, 0, false
+	}
+	return id, attachmentID, true
+}
+
+// GetAttachments 获取条目的附件列表
+func GetAttachments(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if _, err := database.GetPasswordByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	attachments, err := database.GetAttachments(id)
+	if err != nil {
+		log.Printf("获取附件列表失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取附件列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// UploadAttachment 上传附件，multipart表单中的file字段为文件内容。
+// 文件以流的方式分块加密写入附件目录，明文不会落盘
+func UploadAttachment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if _, err := database.GetPasswordByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	maxFile, quota := attachmentLimits()
+	// 为multipart的边界和头部预留少量空间
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFile+(1<<20))
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请使用multipart/form-data上传文件"})
+		return
+	}
+
+	limit, err := reserveAttachmentQuota(maxFile, quota)
+	if err != nil {
+		log.Printf("获取附件占用空间失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "上传附件失败"})
+		return
+	}
+	if limit <= 0 {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "附件总大小已达到上限", "code": "ATTACHMENT_QUOTA_EXCEEDED"})
+		return
+	}
+	// 附件信息保存后已计入占用空间，此时再释放预留
+	defer releaseAttachmentQuota(limit)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "缺少file字段"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "解析上传数据失败"})
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		fileName := strings.TrimSpace(filepath.Base(part.FileName()))
+		if fileName == "" || fileName == "." || fileName == string(filepath.Separator) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "缺少文件名"})
+			return
+		}
+
+		contentType := "application/octet-stream"
+		if mediaType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type")); err == nil {
+			contentType = mediaType
+		}
+
+		blobID, size, err := storeAttachment(part, limit)
+		part.Close()
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if err == errAttachmentTooLarge || errors.As(err, &maxBytesErr) {
+				if limit < maxFile {
+					c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "附件总大小将超过上限", "code": "ATTACHMENT_QUOTA_EXCEEDED"})
+				} else {
+					c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+						"error": fmt.Sprintf("单个附件不能超过 %dMB", maxFile>>20),
+						"code":  "ATTACHMENT_TOO_LARGE",
+					})
+				}
+				return
+			}
+			log.Printf("保存附件失败 ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "上传附件失败"})
+			return
+		}
+
+		attachment := models.Attachment{
+			PasswordID:  id,
+			FileName:    fileName,
+			ContentType: contentType,
+			Size:        size,
+			BlobID:      blobID,
+		}
+		attachmentID, err := database.CreateAttachment(attachment)
+		if err != nil {
+			database.RemoveAttachmentFile(blobID)
+			log.Printf("保存附件信息失败 ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "上传附件失败"})
+			return
+		}
+
+		created, err := database.GetAttachment(id, int(attachmentID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取附件信息失败"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, created)
+		return
+	}
+}
+
+// reserveAttachmentQuota 为一次上传预留配额，返回本次上传允许的最大字节数，不大于0表示配额已用完
+func reserveAttachmentQuota(maxFile, quota int64) (int64, error) {
+	attachmentUploadLock.Lock()
+	defer attachmentUploadLock.Unlock()
+
+	used, err := database.GetAttachmentUsage()
+	if err != nil {
+		return 0, err
+	}
+	limit := min(maxFile, quota-used-attachmentReserved)
+	if limit > 0 {
+		attachmentReserved += limit
+	}
+	return limit, nil
+}
+
+// releaseAttachmentQuota 释放reserveAttachmentQuota预留的配额
+func releaseAttachmentQuota(limit int64) {
+	attachmentUploadLock.Lock()
+	attachmentReserved -= limit
+	attachmentUploadLock.Unlock()
+}
+
+// storeAttachment 将内容加密写入附件目录，超过limit字节时返回errAttachmentTooLarge。
+// 先写入临时文件，完整写入后再重命名，失败时不会留下不完整的文件
+func storeAttachment(r io.Reader, limit int64) (string, int64, error) {
+	blobID, err := database.NewBlobID()
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := os.MkdirAll(database.AttachmentDir(), 0700); err != nil {
+		return "", 0, err
+	}
+
+	finalPath := database.AttachmentPath(blobID)
+	tmpPath := finalPath + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmpPath)
+	defer f.Close()
+
+	// 附件文件绑定到自己的blobID，不能与其他附件互换
+	w, err := utils.NewEncryptWriter(f, []byte(blobID))
+	if err != nil {
+		return "", 0, err
+	}
+
+	size, err := io.Copy(w, io.LimitReader(r, limit+1))
+	if err != nil {
+		return "", 0, err
+	}
+	if size > limit {
+		return "", 0, errAttachmentTooLarge
+	}
+
+	if err := w.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return "", 0, err
+	}
+	if err := f.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return "", 0, err
+	}
+
+	return blobID, size, nil
+}
+
+// DownloadAttachment 解密并下载附件，受保护条目需要二次验证，每次下载都会记录审计日志
+func DownloadAttachment(c *gin.Context) {
+	id, attachmentID, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+
+	password, err := database.GetPasswordByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	if !requireRevealAccess(c, password) {
+		return
+	}
+
+	attachment, err := database.GetAttachment(id, attachmentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到附件"})
+		return
+	}
+
+	f, err := os.Open(database.AttachmentPath(attachment.BlobID))
+	if err != nil {
+		log.Printf("打开附件文件失败 ID=%d: %v", attachmentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "附件文件丢失"})
+		return
+	}
+	defer f.Close()
+
+	decrypted, err := utils.NewDecryptReader(f, []byte(attachment.BlobID))
+	if err != nil {
+		log.Printf("读取附件失败 ID=%d: %v", attachmentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "解密附件失败"})
+		return
+	}
+
+	// 先解密第一块，密钥错误或文件损坏时仍可以返回错误状态码
+	body := bufio.NewReader(decrypted)
+	if _, err := body.Peek(1); err != nil && err != io.EOF {
+		log.Printf("解密附件失败 ID=%d: %v", attachmentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "解密附件失败"})
+		return
+	}
+
+	recordAudit(c, models.AuditActionReveal, id, "attachments."+strconv.Itoa(attachmentID))
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "no-store")
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, nil)
+}
+
+// DeleteAttachment 删除附件
+func DeleteAttachment(c *gin.Context) {
+	id, attachmentID, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+
+	if _, err := database.GetPasswordByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	if err := database.DeleteAttachment(id, attachmentID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到附件"})
+			return
+		}
+		log.Printf("删除附件失败 ID=%d: %v", attachmentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除附件失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "附件已删除"})
+}
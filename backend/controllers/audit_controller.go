@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/gin-gonic/gin"
+)
+
+// 审计日志单次查询的默认和最大条数
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// GetAuditLogs 获取敏感数据访问记录
+func GetAuditLogs(c *gin.Context) {
+	passwordID, _ := strconv.Atoi(c.Query("passwordId"))
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuditLimit)))
+	if err != nil || limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	logs, err := database.GetAuditLogs(passwordID, limit)
+	if err != nil {
+		log.Printf("获取审计日志失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取审计日志失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
+
+// recordAudit 记录一次敏感数据访问，记录失败不影响请求本身
+func recordAudit(c *gin.Context, action string, passwordID int, field string) {
+	err := database.AddAuditLog(models.AuditLog{
+		Action:     action,
+		PasswordID: passwordID,
+		Field:      field,
+		ClientIP:   c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	})
+	if err != nil {
+		log.Printf("记录审计日志失败: %v", err)
+	}
+}
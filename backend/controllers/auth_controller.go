@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+	"unicode/utf8"
 
 	"github.com/007Secret/007Password/database"
 	"github.com/007Secret/007Password/middleware"
@@ -39,9 +41,8 @@ func Login(c *gin.Context) {
 	// 首次使用，数据库文件不存在
 	if os.IsNotExist(err) {
 		log.Printf("数据库文件不存在，这是首次使用")
-		// 验证主密码长度
-		if len(req.MasterPassword) < 6 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Master password must be at least 6 characters"})
+		// 验证主密码强度
+		if !checkMasterPasswordStrength(c, req.MasterPassword) {
 			return
 		}
 
@@ -191,6 +192,73 @@ func ValidateToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"valid": true})
 }
 
+// StepUp 重新验证主密码，签发用于查看受保护条目的短时令牌
+func StepUp(c *gin.Context) {
+	var req struct {
+		MasterPassword string `json:"masterPassword" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求"})
+		return
+	}
+
+	// 使用403而不是401，避免前端误判为登录失效
+	if subtle.ConstantTimeCompare([]byte(middleware.GetMasterPassword()), []byte(req.MasterPassword)) != 1 {
+		log.Printf("二次验证失败：主密码不正确")
+		c.JSON(http.StatusForbidden, gin.H{"error": "主密码不正确", "code": "STEP_UP_FAILED"})
+		return
+	}
+
+	token, expiresAt, err := middleware.GenerateStepUpToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stepUpToken": token,
+		"expiresAt":   expiresAt,
+	})
+}
+
+// CheckPasswordStrength 评估密码强度，供前端在设置或修改主密码时实时展示
+func CheckPasswordStrength(c *gin.Context) {
+	var req struct {
+		Password string `json:"password"`
+	}
+
+	// 接口无需登录，限制请求体大小并拒绝过长的密码，以免占用过多CPU
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 16<<10)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求"})
+		return
+	}
+	if utf8.RuneCountInString(req.Password) > utils.MaxStrengthInputLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("密码长度不能超过%d个字符", utils.MaxStrengthInputLength)})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.EvaluatePasswordStrength(req.Password))
+}
+
+// checkMasterPasswordStrength 检查主密码是否满足强度策略，不满足时返回400及详细反馈
+func checkMasterPasswordStrength(c *gin.Context, password string) bool {
+	strength := utils.EvaluatePasswordStrength(password)
+	if strength.Acceptable {
+		return true
+	}
+
+	log.Printf("主密码强度不足: 分数 %d, 要求 %d", strength.Score, strength.MinScore)
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":    "主密码强度不足",
+		"code":     "WEAK_MASTER_PASSWORD",
+		"strength": strength,
+	})
+	return false
+}
+
 // hashPassword 使用SHA-256哈希密码
 func hashPassword(password string) string {
 	hash := sha256.Sum256([]byte(password))
@@ -427,9 +495,8 @@ func ChangeMasterPassword(c *gin.Context) {
 		return
 	}
 
-	// 验证新密码长度
-	if len(req.NewPassword) < 6 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "新密码长度至少需要6个字符"})
+	// 验证新密码强度
+	if !checkMasterPasswordStrength(c, req.NewPassword) {
 		return
 	}
 
@@ -619,8 +686,7 @@ func SetMasterPassword(c *gin.Context) {
 		return
 	}
 
-	if len(req.MasterPassword) < 6 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least 6 characters"})
+	if !checkMasterPasswordStrength(c, req.MasterPassword) {
 		return
 	}
 
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/gin-gonic/gin"
+)
+
+// 一次批量请求最多包含的操作数
+const maxBatchOperations = 500
+
+// 支持的批量操作
+var batchOps = map[string]bool{
+	database.BatchCreate: true,
+	database.BatchUpdate: true,
+	database.BatchDelete: true,
+	database.BatchTag:    true,
+	database.BatchMove:   true,
+}
+
+// BatchPasswords 在一个事务中执行一组条目操作（新建、修改、删除、标签和移动）。
+// 默认整体模式，任何一项失败都不做任何修改；bestEffort模式下失败的操作单独回滚。
+// 操作只能针对已有条目，不能引用同一批中新建的条目
+func BatchPasswords(c *gin.Context) {
+	var req models.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = models.BatchModeAtomic
+	}
+	if req.Mode != models.BatchModeAtomic && req.Mode != models.BatchModeBestEffort {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的模式: " + req.Mode})
+		return
+	}
+	if len(req.Operations) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有要执行的操作"})
+		return
+	}
+	if len(req.Operations) > maxBatchOperations {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("一次最多执行 %d 项操作", maxBatchOperations)})
+		return
+	}
+
+	ops := make([]database.BatchOp, len(req.Operations))
+	prepared := make([]error, len(req.Operations))
+	shared := sharedUpdateTargets(req.Operations)
+	for i, op := range req.Operations {
+		var err *entryError
+		ops[i], err = prepareBatchOp(c, op, shared)
+		if err != nil {
+			prepared[i] = err
+		}
+	}
+
+	atomic := req.Mode == models.BatchModeAtomic
+	outcomes, err := database.ExecBatch(ops, prepared, atomic)
+	if err != nil {
+		log.Printf("执行批量操作失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "执行批量操作失败"})
+		return
+	}
+
+	resp := models.BatchResponse{Mode: req.Mode, Committed: true, Results: make([]models.BatchResult, len(outcomes))}
+	status := http.StatusOK
+	for i, o := range outcomes {
+		result := batchResult(i, req.Operations[i].Op, o)
+		if o.Err == nil {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+			// 整体模式下以第一个失败操作的状态码作为响应状态码
+			if atomic && result.Status != http.StatusFailedDependency && status == http.StatusOK {
+				status = result.Status
+			}
+		}
+		resp.Results[i] = result
+	}
+	if atomic && resp.Failed > 0 {
+		resp.Committed = false
+	}
+
+	c.JSON(status, resp)
+}
+
+// sharedUpdateTargets 找出在同一批中既被修改又有其他操作的条目。
+// 修改是基于提交前的内容校验的，与其他操作同时作用于同一条目时可能互相覆盖
+func sharedUpdateTargets(ops []models.BatchOperation) map[int]bool {
+	counts := make(map[int]int)
+	updated := make(map[int]bool)
+	for _, op := range ops {
+		if op.Op == database.BatchCreate {
+			continue
+		}
+		counts[op.ID]++
+		if op.Op == database.BatchUpdate {
+			updated[op.ID] = true
+		}
+	}
+
+	shared := make(map[int]bool)
+	for id := range updated {
+		if counts[id] > 1 {
+			shared[id] = true
+		}
+	}
+	return shared
+}
+
+// prepareBatchOp 校验单项操作并准备要保存的内容
+func prepareBatchOp(c *gin.Context, op models.BatchOperation, shared map[int]bool) (database.BatchOp, *entryError) {
+	prepared := database.BatchOp{Kind: op.Op, ID: op.ID}
+	if !batchOps[op.Op] {
+		return prepared, &entryError{status: http.StatusBadRequest, msg: "不支持的操作: " + op.Op}
+	}
+	if op.Op != database.BatchCreate {
+		if op.ID <= 0 {
+			return prepared, &entryError{status: http.StatusBadRequest, msg: "缺少条目ID"}
+		}
+		// 与单独修改和删除条目时的If-Match一致，必须提供修订号，"*" 表示不检查
+		switch {
+		case op.Revision == 0:
+			return prepared, &entryError{status: http.StatusPreconditionRequired, code: "PRECONDITION_REQUIRED", msg: "修改条目需要提供revision，\"*\" 表示不检查修订号"}
+		case op.Revision > 0:
+			prepared.Revision = int(op.Revision)
+		case op.Revision != models.AnyRevision:
+			return prepared, &entryError{status: http.StatusBadRequest, msg: "无效的修订号"}
+		}
+		if shared[op.ID] {
+			return prepared, &entryError{status: http.StatusBadRequest, msg: "同一条目在一批操作中修改时不能再执行其他操作"}
+		}
+	}
+
+	switch op.Op {
+	case database.BatchCreate:
+		var password models.Password
+		if err := json.Unmarshal(op.Data, &password); err != nil {
+			return prepared, &entryError{status: http.StatusBadRequest, msg: "无效的条目数据"}
+		}
+		prepared.ID = 0
+		if err := prepareNewEntry(&password); err != nil {
+			return prepared, err
+		}
+		prepared.Password = password
+
+	case database.BatchUpdate:
+		var patch map[string]interface{}
+		if err := json.Unmarshal(op.Data, &patch); err != nil || patch == nil {
+			return prepared, &entryError{status: http.StatusBadRequest, msg: "补丁须为JSON对象"}
+		}
+		if err := checkPatchFields(patch); err != nil {
+			return prepared, badEntry(err)
+		}
+		existing, err := database.GetPasswordByID(op.ID)
+		if err != nil {
+			return prepared, &entryError{status: http.StatusNotFound, msg: "未找到密码"}
+		}
+		if prepared.Revision != 0 && prepared.Revision != existing.Revision {
+			return prepared, errBatchConflict
+		}
+		password, err := applyPasswordPatch(existing, patch)
+		if err != nil {
+			return prepared, badEntry(err)
+		}
+		if err := prepareEntryUpdate(c, &password, existing); err != nil {
+			return prepared, err
+		}
+		prepared.Password = password
+
+	case database.BatchDelete:
+
+	case database.BatchTag:
+		if len(op.AddTags) == 0 && len(op.RemoveTags) == 0 {
+			return prepared, &entryError{status: http.StatusBadRequest, msg: "缺少要添加或移除的标签"}
+		}
+		prepared.AddTags = op.AddTags
+		prepared.DelTags = op.RemoveTags
+
+	case database.BatchMove:
+		if !folderExists(op.FolderID) {
+			return prepared, errFolderNotFound
+		}
+		prepared.FolderID = op.FolderID
+	}
+	return prepared, nil
+}
+
+// errBatchConflict 批量操作中的条目已被其他客户端修改
+var errBatchConflict = &entryError{status: http.StatusPreconditionFailed, code: "REVISION_CONFLICT", msg: "条目已被其他客户端修改"}
+
+// batchResult 将单项操作的执行结果转换为响应
+func batchResult(index int, op string, o database.BatchOutcome) models.BatchResult {
+	result := models.BatchResult{Index: index, Op: op, ID: o.ID}
+
+	switch err := o.Err.(type) {
+	case nil:
+		result.Status = http.StatusOK
+		if op == database.BatchCreate {
+			result.Status = http.StatusCreated
+		}
+		if op != database.BatchDelete {
+			if p, err := database.GetPasswordByID(o.ID); err == nil {
+				result.Revision = p.Revision
+			}
+		}
+	case *entryError:
+		result.Status = err.status
+		result.Code = err.code
+		result.Error = err.msg
+	default:
+		switch err {
+		case database.ErrBatchAborted:
+			result.Status = http.StatusFailedDependency
+			result.Error = "其他操作失败，本操作未执行"
+		case database.ErrRevisionConflict:
+			result.Status = errBatchConflict.status
+			result.Code = errBatchConflict.code
+			result.Error = errBatchConflict.msg
+		case sql.ErrNoRows:
+			result.Status = http.StatusNotFound
+			result.Error = "未找到密码"
+		default:
+			log.Printf("批量操作失败 序号=%d 操作=%s ID=%d: %v", index, op, o.ID, err)
+			result.Status = http.StatusInternalServerError
+			result.Error = "操作失败"
+		}
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/gin-gonic/gin"
+)
+
+// 定期更换周期和到期查询范围的上限（天）
+const maxExpiryDays = 3650
+
+// 通知列表单次查询的默认和最大条数
+const (
+	defaultNotificationLimit = 100
+	maxNotificationLimit     = 1000
+)
+
+// prepareExpiry 校验条目的过期时间和更换周期
+func prepareExpiry(p *models.Password) error {
+	if p.RotationDays < 0 || p.RotationDays > maxExpiryDays {
+		return fmt.Errorf("更换周期必须在0到%d天之间", maxExpiryDays)
+	}
+	if p.ExpiresAt != nil && p.ExpiresAt.IsZero() {
+		p.ExpiresAt = nil
+	}
+	return nil
+}
+
+// GetExpiringPasswords 获取即将到期和已过期的条目，days默认为提醒设置的提前天数
+func GetExpiringPasswords(c *gin.Context) {
+	days := database.GetIntSetting(database.ExpiryReminderSetting, database.DefaultExpiryReminderDays)
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > maxExpiryDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days必须在0到%d之间", maxExpiryDays)})
+			return
+		}
+		days = n
+	}
+
+	entries, err := database.ExpiringPasswords(days)
+	if err != nil {
+		log.Printf("获取到期条目失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取到期条目失败"})
+		return
+	}
+
+	overdue := 0
+	for _, e := range entries {
+		if e.Overdue {
+			overdue++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"days": days, "entries": entries, "total": len(entries), "overdue": overdue})
+}
+
+// GetNotifications 获取提醒列表，unread=true 只返回未读提醒
+func GetNotifications(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultNotificationLimit)))
+	if err != nil || limit <= 0 {
+		limit = defaultNotificationLimit
+	}
+	if limit > maxNotificationLimit {
+		limit = maxNotificationLimit
+	}
+
+	notes, err := database.GetNotifications(c.Query("unread") == "true", limit)
+	if err != nil {
+		log.Printf("获取提醒失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取提醒失败"})
+		return
+	}
+
+	unread, err := database.CountUnreadNotifications()
+	if err != nil {
+		log.Printf("获取未读提醒数量失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取提醒失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notes, "unread": unread})
+}
+
+// MarkNotificationRead 将提醒标记为已读
+func MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if err := database.MarkNotificationRead(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到提醒"})
+			return
+		}
+		log.Printf("标记提醒已读失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "标记提醒已读失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已标记为已读"})
+}
+
+// MarkAllNotificationsRead 将所有未读提醒标记为已读
+func MarkAllNotificationsRead(c *gin.Context) {
+	n, err := database.MarkAllNotificationsRead()
+	if err != nil {
+		log.Printf("标记全部提醒已读失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "标记提醒已读失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已全部标记为已读", "marked": n})
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/007Secret/007Password/middleware"
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// reveal接口中自定义字段的前缀，如 fields.12
+const customFieldPrefix = "fields."
+
+// validateField 校验字段名称、类型以及值的格式，空值不做格式校验
+func validateField(f models.CustomField) error {
+	if f.Name == "" {
+		return fmt.Errorf("自定义字段名称不能为空")
+	}
+	if f.Value == "" {
+		return nil
+	}
+
+	switch f.Type {
+	case models.FieldTypeText, models.FieldTypeHidden:
+		return nil
+	case models.FieldTypeURL:
+		u, err := url.Parse(f.Value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("字段 %s 不是有效的URL", f.Name)
+		}
+	case models.FieldTypeEmail:
+		if _, err := mail.ParseAddress(f.Value); err != nil {
+			return fmt.Errorf("字段 %s 不是有效的邮箱地址", f.Name)
+		}
+	case models.FieldTypeDate:
+		if _, err := time.Parse("2006-01-02", f.Value); err != nil {
+			return fmt.Errorf("字段 %s 的日期格式应为 YYYY-MM-DD", f.Name)
+		}
+	case models.FieldTypeTOTP:
+		if _, err := utils.ParseOTP(f.Value); err != nil {
+			return fmt.Errorf("字段 %s 不是有效的TOTP/HOTP密钥: %v", f.Name, err)
+		}
+	default:
+		return fmt.Errorf("不支持的字段类型: %s", f.Type)
+	}
+	return nil
+}
+
+// prepareFields 校验提交的自定义字段并加密敏感字段。
+// 客户端回传占位值（带有masked标记）或未修改的明文时沿用原密文，existing为条目当前保存的字段
+func prepareFields(fields []models.CustomField, existing []models.CustomField) ([]models.CustomField, error) {
+	stored := make(map[int]models.CustomField, len(existing))
+	for _, f := range existing {
+		stored[f.ID] = f
+	}
+
+	prepared := make([]models.CustomField, 0, len(fields))
+	for _, f := range fields {
+		f.Name = strings.TrimSpace(f.Name)
+		unchanged := keepsMasked(f.Masked, f.Value)
+		f.Masked = false
+		if f.Type == "" {
+			f.Type = models.FieldTypeText
+		}
+
+		old, hasOld := stored[f.ID]
+		if !hasOld {
+			f.ID = 0
+		}
+
+		// 还原原字段的明文，用于处理占位值和判断是否修改
+		var oldPlain string
+		if hasOld {
+			oldPlain = old.Value
+			if old.IsSecret() && old.Value != "" {
+				decrypted, err := utils.DecryptPassword(old.Value)
+				if err != nil {
+					return nil, fmt.Errorf("解密字段 %s 失败", old.Name)
+				}
+				oldPlain = decrypted
+			}
+			// 占位值只能代表仍为敏感类型的原值，改为非敏感类型时由checkFieldDeclassify拒绝
+			if unchanged && old.IsSecret() && f.IsSecret() {
+				f.Value = oldPlain
+			}
+		}
+
+		// 粘贴的二维码图片识别为其中的otpauth链接后保存
+		if f.Type == models.FieldTypeTOTP && isImageDataURL(f.Value) {
+			text, err := decodeQRDataURL(f.Value)
+			if err != nil {
+				return nil, fmt.Errorf("字段 %s: %v", f.Name, err)
+			}
+			f.Value = text
+		}
+
+		if err := validateField(f); err != nil {
+			return nil, err
+		}
+
+		if f.IsSecret() && f.Value != "" {
+			if hasOld && old.IsSecret() && oldPlain == f.Value {
+				f.Value = old.Value
+			} else {
+				encrypted, err := utils.EncryptPassword(f.Value)
+				if err != nil {
+					return nil, fmt.Errorf("加密字段 %s 失败", f.Name)
+				}
+				f.Value = encrypted
+			}
+		}
+
+		prepared = append(prepared, f)
+	}
+	return prepared, nil
+}
+
+// checkFieldDeclassify 检查是否有敏感字段（隐藏、TOTP）被改为非敏感类型。
+// 改类型后字段以明文保存，并在列表和搜索结果中返回，因此需要二次验证，且必须提交字段的实际值而不是占位值
+func checkFieldDeclassify(c *gin.Context, fields []models.CustomField, existing []models.CustomField) *entryError {
+	stored := make(map[int]models.CustomField, len(existing))
+	for _, f := range existing {
+		stored[f.ID] = f
+	}
+
+	for _, f := range fields {
+		old, ok := stored[f.ID]
+		if f.ID == 0 || !ok || !old.IsSecret() {
+			continue
+		}
+		if f.Type == "" {
+			f.Type = models.FieldTypeText
+		}
+		if f.IsSecret() {
+			continue
+		}
+		if keepsMasked(f.Masked, f.Value) {
+			return &entryError{status: http.StatusBadRequest, msg: fmt.Sprintf("字段 %s 改为非敏感类型时需要提交实际的值", old.Name)}
+		}
+		if !middleware.HasValidStepUp(c) {
+			return &entryError{status: http.StatusForbidden, code: "STEP_UP_REQUIRED", msg: fmt.Sprintf("将敏感字段 %s 改为明文需要重新验证主密码", old.Name)}
+		}
+	}
+	return nil
+}
+
+// maskFields 隐藏敏感字段的值
+func maskFields(fields []models.CustomField) {
+	for i := range fields {
+		if fields[i].IsSecret() {
+			fields[i].Value = maskedPassword
+			fields[i].Masked = true
+		}
+	}
+}
+
+// decryptFields 解密敏感字段的值，解密失败时只记录日志
+func decryptFields(fields []models.CustomField) {
+	for i := range fields {
+		if !fields[i].IsSecret() || fields[i].Value == "" {
+			continue
+		}
+		decrypted, err := utils.DecryptPassword(fields[i].Value)
+		if err != nil {
+			log.Printf("解密自定义字段失败 ID=%d: %v", fields[i].ID, err)
+			continue
+		}
+		fields[i].Value = decrypted
+	}
+}
+
+// revealCustomField 解密条目中指定ID的自定义字段
+func revealCustomField(p models.Password, field string) (string, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(field, customFieldPrefix))
+	if err != nil {
+		return "", errUnknownField
+	}
+
+	for _, f := range p.Fields {
+		if f.ID != id {
+			continue
+		}
+		if !f.IsSecret() || f.Value == "" {
+			return f.Value, nil
+		}
+		return utils.DecryptPassword(f.Value)
+	}
+	return "", errUnknownField
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/gin-gonic/gin"
+)
+
+// folderRequest 创建和修改文件夹的请求数据
+type folderRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID *int   `json:"parentId"`
+}
+
+// GetFolders 获取所有文件夹，客户端根据parentId组装层级结构
+func GetFolders(c *gin.Context) {
+	folders, err := database.GetAllFolders()
+	if err != nil {
+		log.Printf("获取文件夹失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文件夹失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, folders)
+}
+
+// CreateFolder 创建文件夹
+func CreateFolder(c *gin.Context) {
+	var req folderRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "文件夹名称不能为空"})
+		return
+	}
+
+	id, err := database.CreateFolder(strings.TrimSpace(req.Name), req.ParentID)
+	if err != nil {
+		if err == database.ErrParentFolderNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "上级文件夹不存在"})
+			return
+		}
+		log.Printf("创建文件夹失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建文件夹失败"})
+		return
+	}
+
+	folder, err := database.GetFolderByID(int(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文件夹失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, folder)
+}
+
+// UpdateFolder 重命名或移动文件夹，下级文件夹和条目随之移动
+func UpdateFolder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	var req folderRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "文件夹名称不能为空"})
+		return
+	}
+
+	if err := database.UpdateFolder(id, strings.TrimSpace(req.Name), req.ParentID); err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到文件夹"})
+		case database.ErrParentFolderNotFound:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "上级文件夹不存在"})
+		case database.ErrFolderCycle:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不能将文件夹移动到它自身或其子文件夹中"})
+		default:
+			log.Printf("修改文件夹失败 ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "修改文件夹失败"})
+		}
+		return
+	}
+
+	folder, err := database.GetFolderByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文件夹失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, folder)
+}
+
+// DeleteFolder 删除文件夹。mode=reparent（默认）将内容移动到上级文件夹，
+// mode=cascade 删除所有子文件夹并将其中的条目移入回收站
+func DeleteFolder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	mode := c.DefaultQuery("mode", database.FolderDeleteReparent)
+	if mode != database.FolderDeleteReparent && mode != database.FolderDeleteCascade {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode 只能是 reparent 或 cascade"})
+		return
+	}
+
+	trashed, err := database.DeleteFolder(id, mode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到文件夹"})
+			return
+		}
+		log.Printf("删除文件夹失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除文件夹失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "文件夹已删除", "trashed": trashed})
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPasswordHistory 获取条目密码的历史版本，返回解密后的值并记录审计日志
+func GetPasswordHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	password, err := database.GetPasswordByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	if !requireRevealAccess(c, password) {
+		return
+	}
+
+	history, err := database.GetPasswordHistory(id)
+	if err != nil {
+		log.Printf("获取密码历史失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取密码历史失败"})
+		return
+	}
+
+	for i := range history {
+		decrypted, err := utils.DecryptPassword(history[i].Password)
+		if err != nil {
+			log.Printf("解密历史密码失败 ID=%d 历史ID=%d: %v", id, history[i].ID, err)
+			history[i].Password = ""
+			continue
+		}
+		history[i].Password = decrypted
+	}
+
+	recordAudit(c, models.AuditActionReveal, id, "history")
+
+	c.JSON(http.StatusOK, history)
+}
+
+// RestorePasswordHistory 将条目密码恢复为指定的历史版本
+func RestorePasswordHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+	historyID, err := strconv.Atoi(c.Param("historyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的历史版本ID"})
+		return
+	}
+
+	password, err := database.GetPasswordByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	if !requireRevealAccess(c, password) {
+		return
+	}
+
+	if err := database.RestorePasswordHistory(id, historyID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到历史版本"})
+			return
+		}
+		log.Printf("恢复密码历史失败 ID=%d 历史ID=%d: %v", id, historyID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "恢复密码失败"})
+		return
+	}
+
+	recordAudit(c, models.AuditActionRestore, id, "password")
+
+	restoredPassword, err := database.GetPasswordByID(id)
+	if err == nil {
+		refreshRefs(restoredPassword)
+		refreshStrength(&restoredPassword)
+		decryptForResponse(c, &restoredPassword)
+		setPasswordETag(c, restoredPassword)
+	}
+
+	c.JSON(http.StatusOK, restoredPassword)
+}
@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+)
+
+// reveal接口中条目类型字段的前缀，如 itemData.number
+const itemDataPrefix = "itemData."
+
+var (
+	cvvPattern    = regexp.MustCompile(`^\d{3,4}$`)
+	expiryPattern = regexp.MustCompile(`^(\d{2})/(\d{2}|\d{4})$`)
+	ibanPattern   = regexp.MustCompile(`^[A-Z]{2}\d{2}[A-Z0-9]{11,30}$`)
+)
+
+// wifi加密方式的可选值
+var wifiSecurityTypes = map[string]bool{"none": true, "wep": true, "wpa": true, "wpa2": true, "wpa3": true}
+
+// itemField 查找条目类型中的字段定义
+func itemField(itemType, key string) (models.ItemField, bool) {
+	for _, f := range models.ItemSchemas[itemType] {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return models.ItemField{}, false
+}
+
+// normalizeItemValue 按格式规范化字段值，如去除卡号中的空格
+func normalizeItemValue(format, value string) string {
+	value = strings.TrimSpace(value)
+	switch format {
+	case models.ItemFormatCardNumber:
+		return strings.NewReplacer(" ", "", "-", "").Replace(value)
+	case models.ItemFormatIBAN:
+		return strings.ToUpper(strings.ReplaceAll(value, " ", ""))
+	case models.ItemFormatBool, models.ItemFormatWifiSecure:
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+// validateItemValue 校验单个字段值的格式
+func validateItemValue(f models.ItemField, value string) error {
+	switch f.Format {
+	case models.ItemFormatEmail:
+		if _, err := mail.ParseAddress(value); err != nil {
+			return fmt.Errorf("%s 不是有效的邮箱地址", f.Key)
+		}
+	case models.ItemFormatDate:
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("%s 的日期格式应为 YYYY-MM-DD", f.Key)
+		}
+	case models.ItemFormatExpiry:
+		m := expiryPattern.FindStringSubmatch(value)
+		if m == nil {
+			return fmt.Errorf("%s 的格式应为 MM/YYYY", f.Key)
+		}
+		if month, _ := strconv.Atoi(m[1]); month < 1 || month > 12 {
+			return fmt.Errorf("%s 的月份无效", f.Key)
+		}
+	case models.ItemFormatCardNumber:
+		if !validCardNumber(value) {
+			return fmt.Errorf("%s 不是有效的卡号", f.Key)
+		}
+	case models.ItemFormatCVV:
+		if !cvvPattern.MatchString(value) {
+			return fmt.Errorf("%s 应为3到4位数字", f.Key)
+		}
+	case models.ItemFormatIBAN:
+		if !validIBAN(value) {
+			return fmt.Errorf("%s 不是有效的IBAN", f.Key)
+		}
+	case models.ItemFormatBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s 只能是 true 或 false", f.Key)
+		}
+	case models.ItemFormatWifiSecure:
+		if !wifiSecurityTypes[value] {
+			return fmt.Errorf("%s 只能是 none、wep、wpa、wpa2 或 wpa3", f.Key)
+		}
+	}
+	return nil
+}
+
+// validCardNumber 卡号为12到19位数字并通过Luhn校验
+func validCardNumber(number string) bool {
+	if len(number) < 12 || len(number) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// validIBAN 按ISO 13616校验IBAN：前四位移到末尾、字母转为数字后对97取模等于1
+func validIBAN(iban string) bool {
+	if !ibanPattern.MatchString(iban) {
+		return false
+	}
+
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// prepareItem 校验条目类型及其字段，并加密类型字段。
+// existing为条目当前保存的内容（新建时为nil），客户端回传的占位值（条目带有masked标记）会替换为原值
+func prepareItem(p *models.Password, existing *models.Password) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("名称不能为空")
+	}
+
+	if p.ItemType == "" {
+		if existing != nil {
+			p.ItemType = existing.ItemType
+		} else {
+			p.ItemType = models.ItemTypeLogin
+		}
+	}
+	schema, ok := models.ItemSchemas[p.ItemType]
+	if !ok {
+		return fmt.Errorf("不支持的条目类型: %s", p.ItemType)
+	}
+
+	// 更新时未提交类型字段且类型未变，保留原有内容
+	if p.ItemData == nil && existing != nil && existing.ItemType == p.ItemType {
+		p.ItemCipher = existing.ItemCipher
+		p.ItemSearch = existing.ItemSearch
+		return nil
+	}
+
+	var old map[string]string
+	if existing != nil && existing.ItemType == p.ItemType {
+		var err error
+		if old, err = decryptItemData(existing.ItemCipher); err != nil {
+			return fmt.Errorf("解密条目原有内容失败")
+		}
+	}
+
+	data := make(map[string]string)
+	for key, value := range p.ItemData {
+		f, ok := itemField(p.ItemType, key)
+		if !ok {
+			return fmt.Errorf("条目类型 %s 不支持字段 %s", p.ItemType, key)
+		}
+		if f.Secret && keepsMasked(p.Masked, value) {
+			value = old[key]
+		}
+		value = normalizeItemValue(f.Format, value)
+		if value == "" {
+			continue
+		}
+		if err := validateItemValue(f, value); err != nil {
+			return err
+		}
+		data[key] = value
+	}
+
+	for _, f := range schema {
+		if f.Required && data[f.Key] == "" {
+			return fmt.Errorf("条目类型 %s 缺少必填字段 %s", p.ItemType, f.Key)
+		}
+	}
+
+	p.ItemData = nil
+	p.ItemCipher = ""
+	p.ItemSearch = models.ItemSearchText(p.ItemType, data)
+	if len(data) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	encrypted, err := utils.EncryptPassword(string(raw))
+	if err != nil {
+		return fmt.Errorf("加密条目内容失败")
+	}
+	p.ItemCipher = encrypted
+	return nil
+}
+
+// decryptItemData 解密条目类型字段
+func decryptItemData(cipherText string) (map[string]string, error) {
+	data := make(map[string]string)
+	if cipherText == "" {
+		return data, nil
+	}
+
+	raw, err := utils.DecryptPassword(cipherText)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// fillItemData 解密类型字段用于返回，maskSecrets为true时隐藏敏感字段
+func fillItemData(p *models.Password, maskSecrets bool) {
+	if p.ItemCipher == "" {
+		return
+	}
+
+	data, err := decryptItemData(p.ItemCipher)
+	if err != nil {
+		log.Printf("解密条目内容失败 ID=%d: %v", p.ID, err)
+		return
+	}
+
+	if maskSecrets {
+		for key := range data {
+			if f, ok := itemField(p.ItemType, key); ok && f.Secret {
+				data[key] = maskedPassword
+			}
+		}
+	}
+	p.ItemData = data
+}
+
+// revealItemField 解密条目类型中的单个字段
+func revealItemField(p models.Password, field string) (string, error) {
+	key := strings.TrimPrefix(field, itemDataPrefix)
+	if _, ok := itemField(p.ItemType, key); !ok {
+		return "", errUnknownField
+	}
+
+	data, err := decryptItemData(p.ItemCipher)
+	if err != nil {
+		return "", err
+	}
+	return data[key], nil
+}
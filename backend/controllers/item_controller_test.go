@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/007Secret/007Password/models"
+)
+
+func TestValidCardNumber(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"4111111111111111", true},
+		{"5500005555555559", true},
+		{"378282246310005", true},
+		{"4111111111111112", false},
+		{"41111111111", false},          // 太短
+		{"41111111111111111111", false}, // 太长
+		{"4111 1111 1111 1111", false},  // 未规范化
+		{"411111111111111a", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := validCardNumber(tt.number); got != tt.want {
+			t.Errorf("validCardNumber(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestValidIBAN(t *testing.T) {
+	tests := []struct {
+		iban string
+		want bool
+	}{
+		{"GB82WEST12345698765432", true},
+		{"DE89370400440532013000", true},
+		{"FR1420041010050500013M02606", true},
+		{"GB82WEST12345698765433", false}, // 校验位不符
+		{"GB82 WEST 1234 5698 7654 32", false},
+		{"gb82west12345698765432", false},
+		{"GB82", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := validIBAN(tt.iban); got != tt.want {
+			t.Errorf("validIBAN(%q) = %v, want %v", tt.iban, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeItemValue(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		want   string
+	}{
+		{models.ItemFormatCardNumber, " 4111-1111 1111-1111 ", "4111111111111111"},
+		{models.ItemFormatIBAN, "gb82 west 1234 5698 7654 32", "GB82WEST12345698765432"},
+		{models.ItemFormatBool, "TRUE", "true"},
+		{models.ItemFormatWifiSecure, "WPA2", "wpa2"},
+		{"", "  text  ", "text"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeItemValue(tt.format, tt.value); got != tt.want {
+			t.Errorf("normalizeItemValue(%q, %q) = %q, want %q", tt.format, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValidateItemValue(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		valid  bool
+	}{
+		{models.ItemFormatExpiry, "09/2027", true},
+		{models.ItemFormatExpiry, "09/27", true},
+		{models.ItemFormatExpiry, "13/2027", false},
+		{models.ItemFormatExpiry, "9/2027", false},
+		{models.ItemFormatCVV, "123", true},
+		{models.ItemFormatCVV, "1234", true},
+		{models.ItemFormatCVV, "12", false},
+		{models.ItemFormatDate, "2024-02-29", true},
+		{models.ItemFormatDate, "2023-02-29", false},
+		{models.ItemFormatEmail, "a@example.com", true},
+		{models.ItemFormatEmail, "not-an-email", false},
+		{models.ItemFormatBool, "true", true},
+		{models.ItemFormatBool, "yes", false},
+		{models.ItemFormatWifiSecure, "wpa3", true},
+		{models.ItemFormatWifiSecure, "wpa4", false},
+	}
+
+	for _, tt := range tests {
+		err := validateItemValue(models.ItemField{Key: "k", Format: tt.format}, tt.value)
+		if (err == nil) != tt.valid {
+			t.Errorf("validateItemValue(%q, %q) error = %v, want valid %v", tt.format, tt.value, err, tt.valid)
+		}
+	}
+}
@@ -1,11 +1,18 @@
 package controllers
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/007Secret/007Password/database"
 	"github.com/007Secret/007Password/middleware"
@@ -14,10 +21,25 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// GetAllPasswords 获取所有密码
+// GetAllPasswords 获取所有密码，支持按文件夹和标签筛选。
+// 带 limit 或 cursor 参数时分页返回，下一页的游标在 X-Next-Cursor 和 Link 响应头中，
+// 满足筛选条件的总数在 X-Total-Count 响应头中；fields 参数只返回指定的字段
 func GetAllPasswords(c *gin.Context) {
 	log.Printf("获取所有密码列表...")
 
+	filter, ok := parsePasswordFilter(c)
+	if !ok {
+		return
+	}
+	limit, cursor, ok := parsePageParams(c)
+	if !ok {
+		return
+	}
+	fields, ok := parseFieldsParam(c)
+	if !ok {
+		return
+	}
+
 	// 验证数据库连接是否有效
 	if database.DB == nil {
 		log.Printf("💥 数据库连接不存在，尝试重新初始化")
@@ -61,8 +83,12 @@ func GetAllPasswords(c *gin.Context) {
 		}
 	}
 
-	passwords, err := database.GetAllPasswords()
+	page, err := database.ListPasswordsPage(filter, limit, cursor)
 	if err != nil {
+		if err == database.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalidCursorMessage})
+			return
+		}
 		log.Printf("💥 获取密码列表失败: %v", err)
 
 		if err.Error() == "file is not a database" {
@@ -102,77 +128,194 @@ func GetAllPasswords(c *gin.Context) {
 		return
 	}
 
-	log.Printf("✅ 成功获取密码列表，数量: %d", len(passwords))
+	log.Printf("✅ 成功获取密码列表，数量: %d", len(page.Passwords))
+	respondPasswordPage(c, page, fields)
+}
 
-	// 如果没有密码记录，直接返回空数组
-	if len(passwords) == 0 {
-		c.JSON(http.StatusOK, []interface{}{})
-		return
+// respondPasswordPage 返回一页条目，总数和下一页的游标放在响应头中。
+// 列表接口只返回元数据，密码需通过reveal接口单独获取
+func respondPasswordPage(c *gin.Context, page database.PasswordPage, fields []string) {
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+		c.Header("Link", nextPageLink(c, page.NextCursor))
 	}
 
-	// 解密所有密码字段
-	var decryptedPasswords []models.Password
-	decryptFailCount := 0
+	passwords := page.Passwords
+	for i := range passwords {
+		maskPasswordEntry(&passwords[i])
+	}
 
-	for _, pwd := range passwords {
-		pwdCopy := pwd
-		if pwd.Password != "" {
-			log.Printf("🔐 尝试解密密码ID=%d 名称=%s", pwd.ID, pwd.Name)
-			decrypted, err := utils.DecryptPassword(pwd.Password)
-			if err != nil {
-				decryptFailCount++
-				log.Printf("⚠️ 解密密码失败 ID=%d 名称=%s: %v", pwd.ID, pwd.Name, err)
-				// 设置一个特殊的错误消息，让前端知道解密失败的原因
-				errorMsg := "解密失败: " + err.Error()
-				if len(errorMsg) > 50 {
-					errorMsg = errorMsg[:50] + "..."
-				}
-				pwdCopy.Password = errorMsg
-			} else {
-				pwdCopy.Password = decrypted
+	respondProjected(c, passwords, fields)
+}
+
+// 分页大小的默认值和上限
+const (
+	defaultPageSize = 100
+	maxPageSize     = 500
+)
+
+// invalidCursorMessage 游标无法解析或与排序方式不一致时的提示
+const invalidCursorMessage = "无效的游标，游标需与获取时的排序方式一致"
+
+// parsePageParams 解析分页参数 limit 和 cursor，两者都未提供时不分页（limit为0）
+func parsePageParams(c *gin.Context) (int, string, bool) {
+	cursor := c.Query("cursor")
+	v := c.Query("limit")
+	if v == "" {
+		if cursor != "" {
+			return defaultPageSize, cursor, true
+		}
+		return 0, "", true
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit须为1-%d之间的整数", maxPageSize)})
+		return 0, "", false
+	}
+	return limit, cursor, true
+}
+
+// nextPageLink 生成指向下一页的Link响应头，保留当前请求的其他参数
+func nextPageLink(c *gin.Context, cursor string) string {
+	next := *c.Request.URL
+	query := next.Query()
+	query.Set("cursor", cursor)
+	next.RawQuery = query.Encode()
+	return fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI())
+}
+
+// projectableFields 列表和搜索结果可以通过 fields 参数选择的字段，即条目JSON中的字段名
+var projectableFields = jsonFieldNames(reflect.TypeOf(models.SearchResult{}))
+
+// jsonFieldNames 返回结构体（包括嵌入的结构体）序列化为JSON后的字段名
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			for name := range jsonFieldNames(f.Type) {
+				names[name] = true
 			}
+			continue
 		}
-		decryptedPasswords = append(decryptedPasswords, pwdCopy)
-	}
-
-	if decryptFailCount > 0 {
-		log.Printf("⚠️ 警告: %d/%d 个密码解密失败", decryptFailCount, len(passwords))
-		if decryptFailCount == len(passwords) {
-			log.Printf("💥 严重错误: 所有密码解密均失败，可能是主密码错误或数据库加密密钥不匹配")
-
-			// 检查salt值是否存在且有效
-			salt, saltErr := database.GetSetting("password_salt")
-			if saltErr != nil || salt == "" {
-				log.Printf("🔍 检测到盐值不存在或获取失败: %v", saltErr)
-				// 创建新的盐值
-				newSalt := utils.GenerateSalt()
-				if err := database.SetSetting("password_salt", newSalt); err == nil {
-					log.Printf("✅ 已创建新的盐值: %s", newSalt[:8]+"...")
-				} else {
-					log.Printf("❌ 创建新盐值失败: %v", err)
-				}
-			} else {
-				log.Printf("✅ 盐值存在且有效，长度: %d", len(salt))
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// parseFieldsParam 解析 fields 参数（逗号分隔的字段名），未提供时返回nil表示返回全部字段。
+// id总是会返回
+func parseFieldsParam(c *gin.Context) ([]string, bool) {
+	param := c.Query("fields")
+	if param == "" {
+		return nil, true
+	}
+
+	fields := []string{"id"}
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "id" {
+			continue
+		}
+		if !projectableFields[name] {
+			names := make([]string, 0, len(projectableFields))
+			for n := range projectableFields {
+				names = append(names, n)
 			}
+			sort.Strings(names)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("不支持的字段: %s，可用的字段: %s", name, strings.Join(names, ", "))})
+			return nil, false
+		}
+		fields = append(fields, name)
+	}
+	return fields, true
+}
 
-			// 尝试重新初始化数据库
-			currentPassword := middleware.GetMasterPassword()
-			if currentPassword != "" {
-				log.Printf("⚡ 尝试用当前主密码重新初始化数据库")
-				err := database.InitDBWithKey(currentPassword)
-				if err != nil {
-					log.Printf("💥 重新初始化数据库失败: %v", err)
-				} else {
-					log.Printf("✅ 重新初始化数据库成功")
-				}
+// respondProjected 返回列表，fields不为空时每一项只保留这些字段
+func respondProjected[T any](c *gin.Context, items []T, fields []string) {
+	if fields == nil {
+		c.JSON(http.StatusOK, items)
+		return
+	}
+
+	projected := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "生成响应失败"})
+			return
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "生成响应失败"})
+			return
+		}
+		selected := make(map[string]json.RawMessage, len(fields))
+		for _, name := range fields {
+			if v, ok := all[name]; ok {
+				selected[name] = v
 			}
 		}
+		projected = append(projected, selected)
+	}
+	c.JSON(http.StatusOK, projected)
+}
+
+// parsePasswordFilter 解析列表筛选参数：
+// folderId=<id>|none，includeSubfolders=true 包含子文件夹，tag 可重复，需同时满足，type 为条目类型，
+// favorite=true 只返回收藏，sort=recent|frequent|name|updated|created 为排序方式，前面加 - 表示反向
+func parsePasswordFilter(c *gin.Context) (database.PasswordFilter, bool) {
+	var filter database.PasswordFilter
+
+	if itemType := c.Query("type"); itemType != "" {
+		if _, ok := models.ItemSchemas[itemType]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的条目类型: " + itemType})
+			return filter, false
+		}
+		filter.ItemType = itemType
 	}
 
-	log.Printf("✅ 密码列表解密完成，返回结果")
-	c.JSON(http.StatusOK, decryptedPasswords)
+	if folderParam := c.Query("folderId"); folderParam == "none" {
+		filter.Unfiled = true
+	} else if folderParam != "" {
+		folderID, err := strconv.Atoi(folderParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文件夹ID"})
+			return filter, false
+		}
+		filter.FolderID = &folderID
+		filter.IncludeSubfolders = c.Query("includeSubfolders") == "true"
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		if !database.ValidSort(sort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的排序方式: " + sort})
+			return filter, false
+		}
+		filter.Sort = sort
+	}
+
+	filter.Favorite = c.Query("favorite") == "true"
+	filter.Tags = c.QueryArray("tag")
+	return filter, true
+}
+
+// folderExists 检查条目指定的文件夹是否存在，未指定文件夹时返回true
+func folderExists(folderID *int) bool {
+	if folderID == nil {
+		return true
+	}
+	_, err := database.GetFolderByID(*folderID)
+	return err == nil
 }
 
+// errFolderNotFound 条目指定的文件夹不存在
+var errFolderNotFound = &entryError{status: http.StatusBadRequest, msg: "文件夹不存在"}
+
 // 辅助函数: 遮蔽密码用于日志输出
 func maskPassword(password string) string {
 	if len(password) <= 4 {
@@ -181,6 +324,44 @@ func maskPassword(password string) string {
 	return password[:2] + "****" + password[len(password)-2:]
 }
 
+// 列表接口和未二次验证的受保护条目返回的占位密码。
+// 同时返回 masked: true，客户端原样回传占位值和该标记表示未修改；
+// 只有值没有标记时按普通值保存，因此真实的值也可以是 ********
+const maskedPassword = "********"
+
+// keepsMasked 客户端是否原样回传了占位值（值为占位值且带有masked标记）
+func keepsMasked(masked bool, value string) bool {
+	return masked && value == maskedPassword
+}
+
+// maskPasswordEntry 隐藏条目的密码、敏感类型字段和敏感自定义字段
+func maskPasswordEntry(p *models.Password) {
+	p.Password = maskedPassword
+	p.Masked = true
+	fillItemData(p, true)
+	maskFields(p.Fields)
+}
+
+// decryptForResponse 解密条目密码用于返回，受保护条目在未二次验证时隐藏密码
+func decryptForResponse(c *gin.Context, p *models.Password) {
+	if p.Protected && !middleware.HasValidStepUp(c) {
+		maskPasswordEntry(p)
+		return
+	}
+
+	if p.Password != "" {
+		decrypted, err := utils.DecryptPassword(p.Password)
+		if err != nil {
+			log.Printf("Error decrypting password for %s: %v", p.Name, err)
+			// 不返回错误，只是记录日志
+		} else {
+			p.Password = decrypted
+		}
+	}
+	fillItemData(p, false)
+	decryptFields(p.Fields)
+}
+
 // GetPasswordByID 通过ID获取密码
 func GetPasswordByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -196,19 +377,113 @@ func GetPasswordByID(c *gin.Context) {
 	}
 
 	// 解密密码
-	if password.Password != "" {
-		decrypted, err := utils.DecryptPassword(password.Password)
-		if err != nil {
-			log.Printf("Error decrypting password for %s: %v", password.Name, err)
-			// 不返回错误，只是记录日志
-		} else {
-			password.Password = decrypted
-		}
+	decryptForResponse(c, &password)
+	if !password.Masked {
+		recordAudit(c, models.AuditActionView, password.ID, "password")
 	}
 
+	setPasswordETag(c, password)
 	c.JSON(http.StatusOK, password)
 }
 
+// RevealPassword 解密并返回条目的单个字段，每次访问都会记录审计日志
+func RevealPassword(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	var req struct {
+		Field string `json:"field"`
+	}
+	// 请求体可以为空，默认返回密码字段
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+			return
+		}
+	}
+	if req.Field == "" {
+		req.Field = "password"
+	}
+
+	password, err := database.GetPasswordByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	if !requireRevealAccess(c, password) {
+		return
+	}
+
+	value, err := revealField(password, req.Field)
+	if err != nil {
+		if err == errUnknownField {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的字段: " + req.Field})
+			return
+		}
+		log.Printf("解密字段失败 ID=%d 字段=%s: %v", id, req.Field, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "解密失败"})
+		return
+	}
+
+	// 字段中的 {REF:...} 引用在查看时解析为被引用条目的当前值
+	value, err = newRefResolver(c).resolve(value)
+	if err != nil {
+		if err == errRefProtected {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "STEP_UP_REQUIRED"})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditActionReveal, id, req.Field)
+	recordUse(id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":    id,
+		"field": req.Field,
+		"value": value,
+	})
+}
+
+// errUnknownField 请求揭示的字段不存在
+var errUnknownField = errors.New("unknown field")
+
+// requireRevealAccess 检查是否允许揭示条目的敏感字段，受保护条目需要二次验证
+func requireRevealAccess(c *gin.Context, p models.Password) bool {
+	if p.Protected && !middleware.HasValidStepUp(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "查看受保护条目需要重新验证主密码", "code": "STEP_UP_REQUIRED"})
+		return false
+	}
+	return true
+}
+
+// revealField 解密条目的指定字段，自定义字段使用 fields.<字段ID>。
+// 用户名和备注不加密，支持查看是为了解析其中的引用
+func revealField(p models.Password, field string) (string, error) {
+	switch {
+	case field == "password":
+		if p.Password == "" {
+			return "", nil
+		}
+		return utils.DecryptPassword(p.Password)
+	case field == "username":
+		return p.Username, nil
+	case field == "notes":
+		return p.Notes, nil
+	case strings.HasPrefix(field, customFieldPrefix):
+		return revealCustomField(p, field)
+	case strings.HasPrefix(field, itemDataPrefix):
+		return revealItemField(p, field)
+	default:
+		return "", errUnknownField
+	}
+}
+
 // CreatePassword 创建新密码
 func CreatePassword(c *gin.Context) {
 	var password models.Password
@@ -217,14 +492,37 @@ func CreatePassword(c *gin.Context) {
 		return
 	}
 
-	// 加密密码字段
-	if password.Password != "" {
-		encrypted, err := utils.EncryptPassword(password.Password)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "加密密码失败"})
-			return
-		}
-		password.Password = encrypted
+	createEntry(c, password)
+}
+
+// entryError 校验或保存条目失败时返回给客户端的状态码和错误信息
+type entryError struct {
+	status int
+	code   string
+	msg    string
+}
+
+func (e *entryError) Error() string { return e.msg }
+
+// badEntry 将校验错误包装为400
+func badEntry(err error) *entryError {
+	return &entryError{status: http.StatusBadRequest, msg: err.Error()}
+}
+
+// respondEntryError 按entryError返回错误响应
+func respondEntryError(c *gin.Context, err *entryError) {
+	body := gin.H{"error": err.msg}
+	if err.code != "" {
+		body["code"] = err.code
+	}
+	c.JSON(err.status, body)
+}
+
+// createEntry 校验并保存新条目，返回创建后的条目
+func createEntry(c *gin.Context, password models.Password) {
+	if err := prepareNewEntry(&password); err != nil {
+		respondEntryError(c, err)
+		return
 	}
 
 	id, err := database.CreatePassword(password)
@@ -235,17 +533,63 @@ func CreatePassword(c *gin.Context) {
 
 	// 获取创建后的密码记录（带解密密码）
 	createdPassword, err := database.GetPasswordByID(int(id))
-	if err == nil && createdPassword.Password != "" {
-		decrypted, err := utils.DecryptPassword(createdPassword.Password)
-		if err == nil {
-			createdPassword.Password = decrypted
-		}
+	if err == nil {
+		decryptForResponse(c, &createdPassword)
+		setPasswordETag(c, createdPassword)
 	}
 
 	c.JSON(http.StatusCreated, createdPassword)
 }
 
-// UpdatePassword 更新密码
+// prepareNewEntry 校验新条目并加密敏感内容，使其可以直接保存
+func prepareNewEntry(password *models.Password) *entryError {
+	if !folderExists(password.FolderID) {
+		return errFolderNotFound
+	}
+
+	if err := prepareItem(password, nil); err != nil {
+		return badEntry(err)
+	}
+
+	if err := prepareExpiry(password); err != nil {
+		return badEntry(err)
+	}
+
+	fields, err := prepareFields(password.Fields, nil)
+	if err != nil {
+		return badEntry(err)
+	}
+	password.Fields = fields
+
+	uris, err := prepareURIs(password.URIs)
+	if err != nil {
+		return badEntry(err)
+	}
+	password.URIs = uris
+
+	providers, err := prepareProviders(password.Providers, 0)
+	if err != nil {
+		return badEntry(err)
+	}
+	password.Providers = providers
+
+	// 加密密码字段
+	password.Strength = utils.EntryStrengthScore(password.Password)
+	if password.Password != "" {
+		encrypted, err := utils.EncryptPassword(password.Password)
+		if err != nil {
+			return &entryError{status: http.StatusInternalServerError, msg: "加密密码失败"}
+		}
+		password.Password = encrypted
+	}
+
+	if err := prepareRefs(password, password.Fields, 0); err != nil {
+		return badEntry(err)
+	}
+	return nil
+}
+
+// UpdatePassword 更新密码，需要在If-Match请求头中提供获取条目时的ETag
 func UpdatePassword(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -253,41 +597,180 @@ func UpdatePassword(c *gin.Context) {
 		return
 	}
 
+	revision, ok := requireIfMatch(c, id)
+	if !ok {
+		return
+	}
+
 	var password models.Password
 	if err := c.ShouldBindJSON(&password); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
 		return
 	}
 
-	// 加密密码字段
-	if password.Password != "" {
-		encrypted, err := utils.EncryptPassword(password.Password)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "加密密码失败"})
-			return
-		}
-		password.Password = encrypted
+	existing, ok := loadForUpdate(c, id, revision)
+	if !ok {
+		return
 	}
 
-	password.ID = id
+	updateEntry(c, password, existing, revision)
+}
+
+// loadForUpdate 读取要修改的条目，并检查其修订号是否与If-Match中的一致
+func loadForUpdate(c *gin.Context, id, revision int) (models.Password, bool) {
+	existing, err := database.GetPasswordByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return existing, false
+	}
+	if revision != 0 && revision != existing.Revision {
+		respondRevisionConflict(c, id)
+		return existing, false
+	}
+	return existing, true
+}
+
+// updateEntry 校验并保存修改后的条目，PUT和PATCH共用。
+// password为客户端提交的完整内容，集合字段为nil时保留原有内容
+func updateEntry(c *gin.Context, password, existing models.Password, revision int) {
+	id := existing.ID
+	if err := prepareEntryUpdate(c, &password, existing); err != nil {
+		respondEntryError(c, err)
+		return
+	}
+
+	// 请求体中的修订号不可信，以If-Match为准；保存时再次比较，防止校验期间被其他请求修改
+	password.Revision = revision
 	if err := database.UpdatePassword(password); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新密码失败"})
+		switch err {
+		case database.ErrRevisionConflict:
+			respondRevisionConflict(c, id)
+		case sql.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		default:
+			log.Printf("更新密码失败 ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新密码失败"})
+		}
 		return
 	}
 
 	// 获取更新后的密码记录（带解密密码）
 	updatedPassword, err := database.GetPasswordByID(id)
-	if err == nil && updatedPassword.Password != "" {
-		decrypted, err := utils.DecryptPassword(updatedPassword.Password)
-		if err == nil {
-			updatedPassword.Password = decrypted
-		}
+	if err == nil {
+		decryptForResponse(c, &updatedPassword)
+		setPasswordETag(c, updatedPassword)
 	}
 
 	c.JSON(http.StatusOK, updatedPassword)
 }
 
-// DeletePassword 删除密码
+// prepareEntryUpdate 基于条目当前内容校验修改，还原占位值并加密敏感内容，使其可以直接保存
+func prepareEntryUpdate(c *gin.Context, password *models.Password, existing models.Password) *entryError {
+	id := existing.ID
+	password.ID = id
+
+	if !folderExists(password.FolderID) {
+		return errFolderNotFound
+	}
+
+	if err := prepareItem(password, &existing); err != nil {
+		return badEntry(err)
+	}
+
+	if err := prepareExpiry(password); err != nil {
+		return badEntry(err)
+	}
+
+	// 未提交自定义字段时保留原有字段
+	if password.Fields != nil {
+		if err := checkFieldDeclassify(c, password.Fields, existing.Fields); err != nil {
+			return err
+		}
+		fields, err := prepareFields(password.Fields, existing.Fields)
+		if err != nil {
+			return badEntry(err)
+		}
+		password.Fields = fields
+	}
+
+	// 未提交网址时保留原有网址
+	if password.URIs != nil {
+		uris, err := prepareURIs(password.URIs)
+		if err != nil {
+			return badEntry(err)
+		}
+		password.URIs = uris
+	}
+
+	// 未提交登录方式时保留原有登录方式
+	if password.Providers != nil {
+		providers, err := prepareProviders(password.Providers, id)
+		if err != nil {
+			return badEntry(err)
+		}
+		password.Providers = providers
+	}
+
+	// 取消条目的保护需要二次验证
+	if existing.Protected && !password.Protected && !middleware.HasValidStepUp(c) {
+		return &entryError{status: http.StatusForbidden, code: "STEP_UP_REQUIRED", msg: "取消保护需要重新验证主密码"}
+	}
+
+	// 加密密码字段；客户端回传的占位密码或未改变的明文表示未修改，保留原密文，
+	// 避免每次保存都产生新的历史版本
+	if keepsMasked(password.Masked, password.Password) || passwordUnchanged(existing, password.Password) {
+		password.Password = existing.Password
+		password.Strength = existing.Strength
+	} else {
+		password.Strength = utils.EntryStrengthScore(password.Password)
+		if password.Password != "" {
+			encrypted, err := utils.EncryptPassword(password.Password)
+			if err != nil {
+				return &entryError{status: http.StatusInternalServerError, msg: "加密密码失败"}
+			}
+			password.Password = encrypted
+		}
+	}
+
+	// 未提交自定义字段时按原有字段解析引用
+	refFields := password.Fields
+	if refFields == nil {
+		refFields = existing.Fields
+	}
+	if err := prepareRefs(password, refFields, id); err != nil {
+		return badEntry(err)
+	}
+	return nil
+}
+
+// refreshStrength 按条目当前保存的密码重新计算并保存强度分数，用于恢复历史密码等不经过保存校验的修改
+func refreshStrength(p *models.Password) {
+	var score *int
+	if p.Password != "" {
+		decrypted, err := utils.DecryptPassword(p.Password)
+		if err != nil {
+			log.Printf("解密条目密码失败 ID=%d: %v", p.ID, err)
+			return
+		}
+		score = utils.EntryStrengthScore(decrypted)
+	}
+	if err := database.SetPasswordStrength(p.ID, score); err != nil {
+		log.Printf("更新条目密码强度失败 ID=%d: %v", p.ID, err)
+		return
+	}
+	p.Strength = score
+}
+
+// passwordUnchanged 判断提交的明文是否与条目当前密码相同
+func passwordUnchanged(existing models.Password, plaintext string) bool {
+	if existing.Password == "" || plaintext == "" {
+		return false
+	}
+	decrypted, err := utils.DecryptPassword(existing.Password)
+	return err == nil && decrypted == plaintext
+}
+
+// DeletePassword 删除密码，条目移入回收站。需要在If-Match请求头中提供获取条目时的ETag
 func DeletePassword(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -295,40 +778,99 @@ func DeletePassword(c *gin.Context) {
 		return
 	}
 
-	if err := database.DeletePassword(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除密码失败"})
+	revision, ok := requireIfMatch(c, id)
+	if !ok {
+		return
+	}
+
+	if err := database.DeletePassword(id, revision); err != nil {
+		switch err {
+		case database.ErrRevisionConflict:
+			respondRevisionConflict(c, id)
+		case sql.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		default:
+			log.Printf("删除密码失败 ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "删除密码失败"})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password deleted successfully"})
 }
 
-// SearchPasswords 搜索密码
+// 搜索结果数量的默认值和上限
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// SearchPasswords 按结构化搜索语句查找条目，语句中可以包含 site:、tag:、weak:、updated: 等筛选条件，
+// 语法见 database.ParseSearchQuery。关键词在名称、用户名、网址、标签、备注和非敏感自定义字段中搜索，
+// 每个词按前缀匹配，结果按相关度排序并带有匹配内容的摘要。支持与列表相同的筛选参数，limit为结果数量。
+// 搜索只返回相关度最高的limit个条目，不支持游标分页：相关度结合了使用次数，且精确匹配不足时才补充模糊匹配，
+// 排序没有稳定的游标可以续读。需要逐页浏览全部匹配的条目时保存为智能文件夹，按智能文件夹分页获取
 func SearchPasswords(c *gin.Context) {
-	query := c.Query("q")
-	if query == "" {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
 		GetAllPasswords(c)
 		return
 	}
 
-	passwords, err := database.SearchPasswordsByName(query)
+	query, ok := parseSearchQuery(c, q)
+	if !ok {
+		return
+	}
+
+	filter, ok := parsePasswordFilter(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultSearchLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit须为1-%d之间的整数", maxSearchLimit)})
+			return
+		}
+		limit = n
+	}
+
+	respondSearchResults(c, filter, query, database.SearchOptions{Limit: limit, Fuzzy: true})
+}
+
+// parseSearchQuery 解析搜索语句，语句无效时返回400，并指出出错的位置
+func parseSearchQuery(c *gin.Context, q string) (*database.SearchQuery, bool) {
+	query, err := database.ParseSearchQuery(q)
+	if err != nil {
+		if qe, ok := err.(*database.QueryError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": qe.Error(), "code": "INVALID_QUERY", "position": qe.Position, "token": qe.Token})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_QUERY"})
+		}
+		return nil, false
+	}
+	return query, true
+}
+
+// respondSearchResults 执行搜索并返回结果，结果与列表一致，只返回元数据，同样支持 fields 参数
+func respondSearchResults(c *gin.Context, filter database.PasswordFilter, query *database.SearchQuery, opts database.SearchOptions) {
+	fields, ok := parseFieldsParam(c)
+	if !ok {
+		return
+	}
+
+	results, err := database.SearchPasswords(filter, query, opts)
 	if err != nil {
+		log.Printf("搜索密码失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "搜索密码失败"})
 		return
 	}
 
-	// 解密所有密码字段
-	for i, pwd := range passwords {
-		if pwd.Password != "" {
-			decrypted, err := utils.DecryptPassword(pwd.Password)
-			if err != nil {
-				log.Printf("Error decrypting password for %s: %v", pwd.Name, err)
-				// 不返回错误，只是记录日志
-			} else {
-				passwords[i].Password = decrypted
-			}
-		}
+	for i := range results {
+		maskPasswordEntry(&results[i].Password)
 	}
 
-	c.JSON(http.StatusOK, passwords)
+	respondProjected(c, results, fields)
 }
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// mergePatchContentType JSON Merge Patch 的媒体类型，同时也接受 application/json
+const mergePatchContentType = "application/merge-patch+json"
+
+// PATCH可以修改的条目字段；其余字段由服务端维护或有单独的接口（如收藏）
+var patchableFields = map[string]bool{
+	"itemType":     true,
+	"name":         true,
+	"username":     true,
+	"phone":        true,
+	"password":     true,
+	"uris":         true,
+	"providers":    true,
+	"notes":        true,
+	"itemData":     true,
+	"protected":    true,
+	"folderId":     true,
+	"tags":         true,
+	"fields":       true,
+	"expiresAt":    true,
+	"rotationDays": true,
+}
+
+// PatchPassword 按 JSON Merge Patch 修改条目，只需提交要修改的字段，值为null表示清空。
+// 未提交的密码和敏感字段保留原密文，不会被重新加密；需要在If-Match请求头中提供ETag
+func PatchPassword(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(c.ContentType()); err != nil ||
+		(mediaType != mergePatchContentType && mediaType != "application/json") {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "请求体须为 " + mergePatchContentType})
+		return
+	}
+
+	revision, ok := requireIfMatch(c, id)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "补丁须为JSON对象"})
+		return
+	}
+	if err := checkPatchFields(patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, ok := loadForUpdate(c, id, revision)
+	if !ok {
+		return
+	}
+
+	password, err := applyPasswordPatch(existing, patch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updateEntry(c, password, existing, revision)
+}
+
+// checkPatchFields 检查补丁中的字段是否都可以修改
+func checkPatchFields(patch map[string]interface{}) error {
+	var rejected []string
+	for key := range patch {
+		if !patchableFields[key] {
+			rejected = append(rejected, key)
+		}
+	}
+	if len(rejected) == 0 {
+		return nil
+	}
+	sort.Strings(rejected)
+	return fmt.Errorf("不支持修改字段: %v", rejected)
+}
+
+// applyPasswordPatch 将补丁合并到条目当前内容上，得到与PUT请求体等价的条目。
+// 合并的基础是解密后的条目，补丁中的值都按客户端提交的原样保存，未修改的敏感值与原明文相同，
+// 保存时沿用原密文；补丁中没有的集合字段置为nil，保存时保留原有内容
+func applyPasswordPatch(existing models.Password, patch map[string]interface{}) (models.Password, error) {
+	base, err := patchBase(existing)
+	if err != nil {
+		return models.Password{}, err
+	}
+
+	raw, err := json.Marshal(base)
+	if err != nil {
+		return models.Password{}, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return models.Password{}, err
+	}
+
+	merged, err := json.Marshal(utils.MergePatch(doc, patch))
+	if err != nil {
+		return models.Password{}, err
+	}
+	var password models.Password
+	if err := json.Unmarshal(merged, &password); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return models.Password{}, fmt.Errorf("字段 %s 的类型错误，应为 %s", typeErr.Field, typeErr.Type)
+		}
+		return models.Password{}, fmt.Errorf("无效的补丁: %v", err)
+	}
+
+	// 集合字段为nil时保存会保留原有内容：补丁中没有的置为nil；
+	// 值为null的键在合并时被删除，同样得到nil，需要改为空集合才会清空
+	switch v, ok := patch["tags"]; {
+	case !ok:
+		password.Tags = nil
+	case v == nil:
+		password.Tags = []string{}
+	}
+	switch v, ok := patch["fields"]; {
+	case !ok:
+		password.Fields = nil
+	case v == nil:
+		password.Fields = []models.CustomField{}
+	}
+	switch v, ok := patch["uris"]; {
+	case !ok:
+		password.URIs = nil
+	case v == nil:
+		password.URIs = []models.PasswordURI{}
+	}
+	switch v, ok := patch["providers"]; {
+	case !ok:
+		password.Providers = nil
+	case v == nil:
+		password.Providers = []models.PasswordProvider{}
+	}
+	switch v, ok := patch["itemData"]; {
+	case !ok:
+		password.ItemData = nil
+	case v == nil:
+		password.ItemData = map[string]string{}
+	}
+	return password, nil
+}
+
+// patchBase 解密条目的密码、类型字段和敏感自定义字段，作为合并补丁的基础
+func patchBase(existing models.Password) (models.Password, error) {
+	base := existing
+	if base.Password != "" {
+		decrypted, err := utils.DecryptPassword(base.Password)
+		if err != nil {
+			return models.Password{}, fmt.Errorf("解密条目密码失败")
+		}
+		base.Password = decrypted
+	}
+
+	data, err := decryptItemData(existing.ItemCipher)
+	if err != nil {
+		return models.Password{}, fmt.Errorf("解密条目内容失败")
+	}
+	base.ItemData = data
+
+	base.Fields = append([]models.CustomField(nil), existing.Fields...)
+	for i, f := range base.Fields {
+		if !f.IsSecret() || f.Value == "" {
+			continue
+		}
+		decrypted, err := utils.DecryptPassword(f.Value)
+		if err != nil {
+			return models.Password{}, fmt.Errorf("解密字段 %s 失败", f.Name)
+		}
+		base.Fields[i].Value = decrypted
+	}
+	return base, nil
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// patchExisting 不含密文的条目，合并补丁时不需要解密
+func patchExisting() models.Password {
+	accountID := 7
+	return models.Password{
+		ID:        1,
+		ItemType:  models.ItemTypeLogin,
+		Name:      "GitHub",
+		Username:  "alice",
+		Notes:     "old notes",
+		Tags:      []string{"work", "dev"},
+		Fields:    []models.CustomField{{ID: 3, Name: "team", Type: models.FieldTypeText, Value: "core"}},
+		URIs:      []models.PasswordURI{{ID: 4, URI: "https://github.com", Match: models.URIMatchDomain}},
+		Providers: []models.PasswordProvider{{ProviderID: 2, AccountID: &accountID}},
+		ItemData:  map[string]string{},
+		Revision:  5,
+	}
+}
+
+func mustPatch(t *testing.T, body string) models.Password {
+	t.Helper()
+	var patch map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &patch); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPatchFields(patch); err != nil {
+		t.Fatalf("checkPatchFields(%s): %v", body, err)
+	}
+	p, err := applyPasswordPatch(patchExisting(), patch)
+	if err != nil {
+		t.Fatalf("applyPasswordPatch(%s): %v", body, err)
+	}
+	return p
+}
+
+// 集合字段为nil表示保留原有内容，空集合表示清空
+func TestApplyPasswordPatchCollections(t *testing.T) {
+	tests := []struct {
+		name string
+		get  func(models.Password) interface{}
+		// 分别为不提交、提交null和提交新值时的补丁及期望结果
+		replace string
+		want    interface{}
+	}{
+		{
+			name:    "tags",
+			get:     func(p models.Password) interface{} { return p.Tags },
+			replace: `{"tags":["home"]}`,
+			want:    []string{"home"},
+		},
+		{
+			name:    "fields",
+			get:     func(p models.Password) interface{} { return p.Fields },
+			replace: `{"fields":[{"name":"pin","type":"hidden","value":"1234"}]}`,
+			want:    []models.CustomField{{Name: "pin", Type: models.FieldTypeHidden, Value: "1234"}},
+		},
+		{
+			name:    "uris",
+			get:     func(p models.Password) interface{} { return p.URIs },
+			replace: `{"uris":[{"uri":"https://gitlab.com","match":"host"}]}`,
+			want:    []models.PasswordURI{{URI: "https://gitlab.com", Match: models.URIMatchHost}},
+		},
+		{
+			name:    "providers",
+			get:     func(p models.Password) interface{} { return p.Providers },
+			replace: `{"providers":[{"providerId":9,"accountId":null}]}`,
+			want:    []models.PasswordProvider{{ProviderID: 9}},
+		},
+		{
+			name:    "itemData",
+			get:     func(p models.Password) interface{} { return p.ItemData },
+			replace: `{"itemData":{"number":"4111111111111111"}}`,
+			want:    map[string]string{"number": "4111111111111111"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			absent := tt.get(mustPatch(t, `{"name":"renamed"}`))
+			if !reflect.ValueOf(absent).IsNil() {
+				t.Errorf("absent: got %#v, want nil (keep existing)", absent)
+			}
+
+			cleared := tt.get(mustPatch(t, `{"`+tt.name+`":null}`))
+			if v := reflect.ValueOf(cleared); v.IsNil() || v.Len() != 0 {
+				t.Errorf("null: got %#v, want an empty non-nil collection", cleared)
+			}
+
+			if got := tt.get(mustPatch(t, tt.replace)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replace: got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPasswordPatchScalars(t *testing.T) {
+	p := mustPatch(t, `{"name":"renamed","notes":null,"folderId":3}`)
+	if p.Name != "renamed" || p.Notes != "" || p.FolderID == nil || *p.FolderID != 3 {
+		t.Errorf("got name %q notes %q folder %v", p.Name, p.Notes, p.FolderID)
+	}
+	if p.Username != "alice" {
+		t.Errorf("username = %q, want unchanged", p.Username)
+	}
+}
+
+func TestCheckPatchFields(t *testing.T) {
+	for _, body := range []string{`{"id":2}`, `{"revision":9}`, `{"createdAt":null}`, `{"masked":true}`} {
+		var patch map[string]interface{}
+		json.Unmarshal([]byte(body), &patch)
+		if err := checkPatchFields(patch); err == nil {
+			t.Errorf("checkPatchFields(%s) succeeded, want error", body)
+		}
+	}
+}
+
+func TestApplyPasswordPatchTypeError(t *testing.T) {
+	var patch map[string]interface{}
+	json.Unmarshal([]byte(`{"tags":"work"}`), &patch)
+	if _, err := applyPasswordPatch(patchExisting(), patch); err == nil {
+		t.Error("applyPasswordPatch accepted a string for tags")
+	}
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/gin-gonic/gin"
+)
+
+// providerRequest 创建和重命名身份提供方的请求数据
+type providerRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// GetIdentityProviders 获取所有身份提供方及使用数量
+func GetIdentityProviders(c *gin.Context) {
+	providers, err := database.GetIdentityProviders()
+	if err != nil {
+		log.Printf("获取身份提供方失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取身份提供方失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, providers)
+}
+
+// CreateIdentityProvider 创建身份提供方，如 Apple、Microsoft 或企业SSO
+func CreateIdentityProvider(c *gin.Context) {
+	var req providerRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "身份提供方名称不能为空"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	id, err := database.CreateIdentityProvider(name)
+	if err != nil {
+		if err == database.ErrProviderExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "身份提供方已存在"})
+			return
+		}
+		log.Printf("创建身份提供方失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建身份提供方失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "name": name})
+}
+
+// RenameIdentityProvider 重命名身份提供方
+func RenameIdentityProvider(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	var req providerRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "身份提供方名称不能为空"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := database.RenameIdentityProvider(id, name); err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到身份提供方"})
+		case database.ErrProviderExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "身份提供方已存在"})
+		default:
+			log.Printf("重命名身份提供方失败 ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "重命名身份提供方失败"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "name": name})
+}
+
+// DeleteIdentityProvider 删除身份提供方，条目上的该登录方式一并移除
+func DeleteIdentityProvider(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if err := database.DeleteIdentityProvider(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到身份提供方"})
+			return
+		}
+		log.Printf("删除身份提供方失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除身份提供方失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "身份提供方已删除"})
+}
+
+// prepareProviders 校验条目的登录方式：身份提供方必须存在，关联的SSO账号必须是
+// 其他未删除的条目。selfID为当前条目ID，新建条目时为0
+func prepareProviders(links []models.PasswordProvider, selfID int) ([]models.PasswordProvider, error) {
+	seen := make(map[int]bool, len(links))
+	prepared := make([]models.PasswordProvider, 0, len(links))
+	for _, link := range links {
+		provider, err := database.GetIdentityProvider(link.ProviderID)
+		if err != nil {
+			return nil, fmt.Errorf("身份提供方 %d 不存在", link.ProviderID)
+		}
+		if seen[link.ProviderID] {
+			return nil, fmt.Errorf("登录方式 %s 重复", provider.Name)
+		}
+		seen[link.ProviderID] = true
+
+		if link.AccountID != nil {
+			if *link.AccountID == selfID {
+				return nil, fmt.Errorf("登录方式 %s 不能关联条目自身", provider.Name)
+			}
+			if _, err := database.GetPasswordByID(*link.AccountID); err != nil {
+				return nil, fmt.Errorf("登录方式 %s 关联的账号条目不存在", provider.Name)
+			}
+		}
+
+		prepared = append(prepared, models.PasswordProvider{ProviderID: link.ProviderID, Name: provider.Name, AccountID: link.AccountID})
+	}
+	return prepared, nil
+}
+
+// GetProviderExposure 评估身份提供方被盗用的影响范围：返回直接或经由关联SSO账号间接
+// 使用它登录的全部条目，并标出没有密码、只能通过SSO登录的条目
+func GetProviderExposure(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	provider, err := database.GetIdentityProvider(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到身份提供方"})
+		return
+	}
+
+	entries, err := database.ProviderExposure(id)
+	if err != nil {
+		log.Printf("计算身份提供方影响范围失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "计算影响范围失败"})
+		return
+	}
+
+	noFallback := 0
+	for _, e := range entries {
+		if e.NoPasswordFallback {
+			noFallback++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"provider":           gin.H{"id": provider.ID, "name": provider.Name},
+		"entries":            entries,
+		"total":              len(entries),
+		"noPasswordFallback": noFallback,
+	})
+}
@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/middleware"
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// 引用语法与KeePass一致：{REF:<字段>@I:<条目ID>}，字段为 T（名称）、U（用户名）、
+// P（密码）、A（网址）或 N（备注）。目前只支持按条目ID查找
+var (
+	refPattern    = regexp.MustCompile(`(?i)\{REF:([TUPAN])@I:(\d+)\}`)
+	refAnyPattern = regexp.MustCompile(`(?i)\{REF:[^}]*\}`)
+)
+
+// 引用链的最大深度，超过时视为错误
+const maxRefDepth = 5
+
+var refFieldCodes = map[string]string{
+	"T": models.RefFieldTitle,
+	"U": models.RefFieldUsername,
+	"P": models.RefFieldPassword,
+	"A": models.RefFieldURL,
+	"N": models.RefFieldNotes,
+}
+
+var (
+	errRefProtected = errors.New("引用的条目受保护，需要重新验证主密码")
+	errRefCycle     = errors.New("字段引用存在循环")
+	errRefDepth     = fmt.Errorf("字段引用层级超过 %d 层", maxRefDepth)
+)
+
+// parseRefs 解析值中的所有引用，返回目标条目ID和目标字段。
+// 形如 {REF:...} 但不符合支持的语法时返回错误，避免用户误以为引用已生效
+func parseRefs(value string) ([]models.FieldRef, error) {
+	if !strings.Contains(strings.ToUpper(value), "{REF:") {
+		return nil, nil
+	}
+
+	var refs []models.FieldRef
+	for _, m := range refAnyPattern.FindAllString(value, -1) {
+		sub := refPattern.FindStringSubmatch(m)
+		if sub == nil || sub[0] != m {
+			return nil, fmt.Errorf("不支持的引用 %s，格式应为 {REF:P@I:<条目ID>}", m)
+		}
+		id, err := strconv.Atoi(sub[2])
+		if err != nil {
+			return nil, fmt.Errorf("不支持的引用 %s", m)
+		}
+		refs = append(refs, models.FieldRef{TargetID: id, TargetField: refFieldCodes[strings.ToUpper(sub[1])]})
+	}
+	return refs, nil
+}
+
+// refSources 返回条目中可以包含引用的字段及其明文，敏感内容在此解密。
+// fields为条目最终保存的自定义字段
+func refSources(p models.Password, fields []models.CustomField) (map[string]string, error) {
+	sources := map[string]string{
+		models.RefFieldUsername: p.Username,
+		models.RefFieldNotes:    p.Notes,
+	}
+	if p.Password != "" {
+		plain, err := utils.DecryptPassword(p.Password)
+		if err != nil {
+			return nil, fmt.Errorf("解密密码失败")
+		}
+		sources[models.RefFieldPassword] = plain
+	}
+	for _, f := range fields {
+		if f.Type != models.FieldTypeText && f.Type != models.FieldTypeHidden {
+			continue
+		}
+		value := f.Value
+		if f.IsSecret() && value != "" {
+			plain, err := utils.DecryptPassword(value)
+			if err != nil {
+				return nil, fmt.Errorf("解密自定义字段 %s 失败", f.Name)
+			}
+			value = plain
+		}
+		sources[models.RefCustomFieldPrefix+f.Name] = value
+	}
+	return sources, nil
+}
+
+// collectRefs 收集条目字段中的所有引用
+func collectRefs(p models.Password, fields []models.CustomField) ([]models.FieldRef, error) {
+	sources, err := refSources(p, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []models.FieldRef{}
+	for field, value := range sources {
+		found, err := parseRefs(value)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range found {
+			r.Field = field
+			refs = append(refs, r)
+		}
+	}
+	return refs, nil
+}
+
+// prepareRefs 解析条目中的引用并校验：目标条目必须存在且不在回收站中，
+// 不能引用自身，也不能形成循环。selfID为当前条目ID，新建条目时为0
+func prepareRefs(p *models.Password, fields []models.CustomField, selfID int) error {
+	refs, err := collectRefs(*p, fields)
+	if err != nil {
+		return err
+	}
+
+	checked := make(map[int]bool)
+	for _, r := range refs {
+		if checked[r.TargetID] {
+			continue
+		}
+		checked[r.TargetID] = true
+
+		if r.TargetID == selfID {
+			return fmt.Errorf("字段不能引用条目自身")
+		}
+		target, err := database.GetPasswordByID(r.TargetID)
+		if err != nil || target.DeletedAt != nil {
+			return fmt.Errorf("引用的条目 %d 不存在或已删除", r.TargetID)
+		}
+		if selfID != 0 {
+			cycle, err := database.RefReaches(r.TargetID, selfID)
+			if err != nil {
+				return fmt.Errorf("检查循环引用失败")
+			}
+			if cycle {
+				return errRefCycle
+			}
+		}
+	}
+
+	p.Refs = refs
+	return nil
+}
+
+// refreshRefs 按条目当前保存的内容重新记录引用关系，用于恢复历史密码等不经过保存校验的修改
+func refreshRefs(p models.Password) {
+	refs, err := collectRefs(p, p.Fields)
+	if err == nil {
+		err = database.SetPasswordRefs(p.ID, refs)
+	}
+	if err != nil {
+		log.Printf("更新条目引用关系失败 ID=%d: %v", p.ID, err)
+	}
+}
+
+// refResolver 在查看字段时解析引用，同一次请求中读取过的条目会被缓存。
+// 通过引用解密的密码与直接查看一样受条目保护的限制，并记录到被引用条目的审计日志
+type refResolver struct {
+	c              *gin.Context
+	allowProtected bool
+	entries        map[int]models.Password
+	audited        map[int]bool
+}
+
+func newRefResolver(c *gin.Context) *refResolver {
+	return &refResolver{
+		c:              c,
+		allowProtected: middleware.HasValidStepUp(c),
+		entries:        make(map[int]models.Password),
+		audited:        make(map[int]bool),
+	}
+}
+
+// resolve 将值中的引用替换为目标字段的明文，目标字段中的引用会继续解析
+func (r *refResolver) resolve(value string) (string, error) {
+	return r.resolveDepth(value, 0, map[string]bool{})
+}
+
+func (r *refResolver) resolveDepth(value string, depth int, visiting map[string]bool) (string, error) {
+	matches := refPattern.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return value, nil
+	}
+	if depth >= maxRefDepth {
+		return "", errRefDepth
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		code := strings.ToUpper(value[m[2]:m[3]])
+		id, err := strconv.Atoi(value[m[4]:m[5]])
+		if err != nil {
+			return "", fmt.Errorf("无效的引用 %s", value[m[0]:m[1]])
+		}
+
+		key := code + "@" + strconv.Itoa(id)
+		if visiting[key] {
+			return "", errRefCycle
+		}
+		visiting[key] = true
+		resolved, err := r.targetValue(id, refFieldCodes[code])
+		if err == nil {
+			resolved, err = r.resolveDepth(resolved, depth+1, visiting)
+		}
+		delete(visiting, key)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(value[last:m[0]])
+		b.WriteString(resolved)
+		last = m[1]
+	}
+	b.WriteString(value[last:])
+	return b.String(), nil
+}
+
+// targetValue 读取目标条目指定字段的明文（未解析其中的引用）
+func (r *refResolver) targetValue(id int, field string) (string, error) {
+	p, ok := r.entries[id]
+	if !ok {
+		var err error
+		p, err = database.GetPasswordByID(id)
+		if err != nil || p.DeletedAt != nil {
+			return "", fmt.Errorf("引用的条目 %d 不存在或已删除", id)
+		}
+		r.entries[id] = p
+	}
+
+	switch field {
+	case models.RefFieldTitle:
+		return p.Name, nil
+	case models.RefFieldUsername:
+		return p.Username, nil
+	case models.RefFieldNotes:
+		return p.Notes, nil
+	case models.RefFieldURL:
+		if len(p.URIs) == 0 {
+			return "", nil
+		}
+		return p.URIs[0].URI, nil
+	case models.RefFieldPassword:
+		if p.Protected && !r.allowProtected {
+			return "", errRefProtected
+		}
+		if p.Password == "" {
+			return "", nil
+		}
+		value, err := utils.DecryptPassword(p.Password)
+		if err != nil {
+			return "", err
+		}
+		if !r.audited[id] {
+			r.audited[id] = true
+			recordAudit(r.c, models.AuditActionReveal, id, "password")
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("不支持的引用字段: %s", field)
+}
+
+// rewriteImportRefs 将导入条目中引用的导出文件条目ID替换为导入后的新ID，
+// 引用的条目不在导入文件中时改为0，使其无法解析而不是指向无关的条目
+func rewriteImportRefs(p *models.Password, newIDs map[int]int) (bool, error) {
+	changed := false
+	rewrite := func(value string) string {
+		return refPattern.ReplaceAllStringFunc(value, func(m string) string {
+			sub := refPattern.FindStringSubmatch(m)
+			oldID, _ := strconv.Atoi(sub[2])
+			changed = true
+			return fmt.Sprintf("{REF:%s@I:%d}", strings.ToUpper(sub[1]), newIDs[oldID])
+		})
+	}
+
+	p.Username = rewrite(p.Username)
+	p.Notes = rewrite(p.Notes)
+	if p.Password != "" {
+		plain, err := utils.DecryptPassword(p.Password)
+		if err != nil {
+			return false, err
+		}
+		if rewritten := rewrite(plain); rewritten != plain {
+			if p.Password, err = utils.EncryptPassword(rewritten); err != nil {
+				return false, err
+			}
+		}
+	}
+	for i := range p.Fields {
+		f := &p.Fields[i]
+		if f.Type != models.FieldTypeText && f.Type != models.FieldTypeHidden {
+			continue
+		}
+		if !f.IsSecret() {
+			f.Value = rewrite(f.Value)
+			continue
+		}
+		plain, err := utils.DecryptPassword(f.Value)
+		if err != nil {
+			return false, err
+		}
+		if rewritten := rewrite(plain); rewritten != plain {
+			if f.Value, err = utils.EncryptPassword(rewritten); err != nil {
+				return false, err
+			}
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+
+	refs, err := collectRefs(*p, p.Fields)
+	if err != nil {
+		return false, err
+	}
+	// 指向导入文件之外的引用已改为0，不记录引用关系
+	p.Refs = make([]models.FieldRef, 0, len(refs))
+	for _, r := range refs {
+		if r.TargetID != 0 {
+			p.Refs = append(p.Refs, r)
+		}
+	}
+	return true, nil
+}
+
+// GetRefDependents 列出引用了指定条目的条目，修改该条目会影响这些条目查看时的值
+func GetRefDependents(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if _, err := database.GetPasswordByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	dependents, err := database.GetRefDependents(id)
+	if err != nil {
+		log.Printf("获取引用条目失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取引用条目失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "dependents": dependents, "total": len(dependents)})
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/gin-gonic/gin"
+)
+
+// setPasswordETag 在响应头中返回条目当前修订号，客户端修改或删除条目时通过If-Match回传
+func setPasswordETag(c *gin.Context, p models.Password) {
+	if p.Revision > 0 {
+		c.Header("ETag", fmt.Sprintf(`"%d"`, p.Revision))
+	}
+}
+
+// parseETagRevision 从ETag中读取修订号，接受 "3" 和 W/"3" 两种形式
+func parseETagRevision(tag string) (int, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	revision, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || revision <= 0 {
+		return 0, false
+	}
+	return revision, true
+}
+
+// requireIfMatch 读取If-Match请求头中客户端所见的修订号，* 表示不检查修订号，返回0。
+// 缺少请求头时返回428，无法识别的ETag视为不匹配，返回412和服务端当前版本
+func requireIfMatch(c *gin.Context, id int) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "修改条目需要在If-Match请求头中提供ETag", "code": "PRECONDITION_REQUIRED"})
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	revision, ok := parseETagRevision(header)
+	if !ok {
+		respondRevisionConflict(c, id)
+		return 0, false
+	}
+	return revision, true
+}
+
+// respondRevisionConflict 条目已被其他客户端修改时返回412，响应中包含服务端当前版本和ETag。
+// 当前版本与列表一样隐藏密码和敏感字段，明文需通过会记录审计日志的查看接口重新获取
+func respondRevisionConflict(c *gin.Context, id int) {
+	current, err := database.GetPasswordByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	maskPasswordEntry(&current)
+	setPasswordETag(c, current)
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":   "条目已被其他客户端修改，请基于最新版本重新编辑",
+		"code":    "REVISION_CONFLICT",
+		"current": current,
+	})
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseETagRevision(t *testing.T) {
+	tests := []struct {
+		tag      string
+		revision int
+		ok       bool
+	}{
+		{`"3"`, 3, true},
+		{` W/"12" `, 12, true},
+		{`"0"`, 0, false},
+		{`"-1"`, 0, false},
+		{`3`, 0, false},
+		{`"x"`, 0, false},
+		{`"3`, 0, false},
+		{`""`, 0, false},
+		{`"`, 0, false},
+		{`w/"3"`, 0, false},
+		{`"3", "4"`, 0, false},
+	}
+
+	for _, tt := range tests {
+		revision, ok := parseETagRevision(tt.tag)
+		if revision != tt.revision || ok != tt.ok {
+			t.Errorf("parseETagRevision(%q) = %d, %v; want %d, %v", tt.tag, revision, ok, tt.revision, tt.ok)
+		}
+	}
+}
+
+// 不涉及读取当前版本的情况：缺少请求头、* 和有效的ETag
+func TestRequireIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		header   string
+		revision int
+		ok       bool
+		status   int
+	}{
+		{"", 0, false, http.StatusPreconditionRequired},
+		{"  ", 0, false, http.StatusPreconditionRequired},
+		{"*", 0, true, http.StatusOK},
+		{`"7"`, 7, true, http.StatusOK},
+		{`W/"7"`, 7, true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/api/passwords/1", nil)
+		if tt.header != "" {
+			c.Request.Header.Set("If-Match", tt.header)
+		}
+
+		revision, ok := requireIfMatch(c, 1)
+		if revision != tt.revision || ok != tt.ok || w.Code != tt.status {
+			t.Errorf("requireIfMatch(%q) = %d, %v with status %d; want %d, %v with %d",
+				tt.header, revision, ok, w.Code, tt.revision, tt.ok, tt.status)
+		}
+	}
+}
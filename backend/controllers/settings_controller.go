@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/gin-gonic/gin"
+)
+
+// userSetting 描述一个可以通过API调整的整数配置项
+type userSetting struct {
+	key          string
+	defaultValue int
+	min          int
+	max          int
+	// 修改成功后执行的操作，如按新的保留数量清理数据
+	onChange func() error
+}
+
+// userSettings 可调整的配置项，以API中的字段名为键
+var userSettings = map[string]userSetting{
+	"passwordHistoryRetention": {
+		key:          database.HistoryRetentionSetting,
+		defaultValue: database.DefaultHistoryRetention,
+		min:          0,
+		max:          100,
+		onChange:     database.PruneAllPasswordHistory,
+	},
+	"trashRetentionDays": {
+		key:          database.TrashRetentionSetting,
+		defaultValue: database.DefaultTrashRetentionDays,
+		min:          0,
+		max:          3650,
+	},
+	"expiryReminderDays": {
+		key:          database.ExpiryReminderSetting,
+		defaultValue: database.DefaultExpiryReminderDays,
+		min:          1,
+		max:          365,
+	},
+}
+
+// GetSettings 获取可调整的配置项
+func GetSettings(c *gin.Context) {
+	settings := make(map[string]int, len(userSettings))
+	for name, s := range userSettings {
+		settings[name] = database.GetIntSetting(s.key, s.defaultValue)
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings 修改配置项，只需提交要修改的字段
+func UpdateSettings(c *gin.Context) {
+	var req map[string]int
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	// 先全部校验再写入，避免部分修改
+	for name, value := range req {
+		s, ok := userSettings[name]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的配置项: " + name})
+			return
+		}
+		if value < s.min || value > s.max {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": name + " 的取值范围为 " + strconv.Itoa(s.min) + " 到 " + strconv.Itoa(s.max),
+			})
+			return
+		}
+	}
+
+	for name, value := range req {
+		s := userSettings[name]
+		if err := database.SetSetting(s.key, strconv.Itoa(value)); err != nil {
+			log.Printf("保存配置项 %s 失败: %v", s.key, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "保存配置失败"})
+			return
+		}
+		if s.onChange != nil {
+			if err := s.onChange(); err != nil {
+				log.Printf("应用配置项 %s 失败: %v", s.key, err)
+			}
+		}
+	}
+
+	GetSettings(c)
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/gin-gonic/gin"
+)
+
+// smartFolderRequest 创建和修改智能文件夹的请求数据
+type smartFolderRequest struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// bindSmartFolder 读取并校验智能文件夹的名称和搜索语句
+func bindSmartFolder(c *gin.Context) (smartFolderRequest, bool) {
+	var req smartFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return req, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "智能文件夹名称不能为空"})
+		return req, false
+	}
+	if req.Query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "搜索语句不能为空"})
+		return req, false
+	}
+	query, ok := parseSearchQuery(c, req.Query)
+	if !ok {
+		return req, false
+	}
+	if query.Empty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "搜索语句不能为空"})
+		return req, false
+	}
+	return req, true
+}
+
+// GetSmartFolders 获取所有智能文件夹及当前匹配的条目数量
+func GetSmartFolders(c *gin.Context) {
+	folders, err := database.GetAllSmartFolders()
+	if err != nil {
+		log.Printf("获取智能文件夹失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取智能文件夹失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, folders)
+}
+
+// CreateSmartFolder 将搜索语句保存为智能文件夹
+func CreateSmartFolder(c *gin.Context) {
+	req, ok := bindSmartFolder(c)
+	if !ok {
+		return
+	}
+
+	id, err := database.CreateSmartFolder(req.Name, req.Query)
+	if err != nil {
+		if err == database.ErrSmartFolderExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "智能文件夹已存在"})
+			return
+		}
+		log.Printf("创建智能文件夹失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建智能文件夹失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "name": req.Name, "query": req.Query})
+}
+
+// UpdateSmartFolder 修改智能文件夹的名称和搜索语句
+func UpdateSmartFolder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	req, ok := bindSmartFolder(c)
+	if !ok {
+		return
+	}
+
+	if err := database.UpdateSmartFolder(id, req.Name, req.Query); err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到智能文件夹"})
+		case database.ErrSmartFolderExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "智能文件夹已存在"})
+		default:
+			log.Printf("修改智能文件夹失败 ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "修改智能文件夹失败"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "name": req.Name, "query": req.Query})
+}
+
+// DeleteSmartFolder 删除智能文件夹，其中的条目不受影响
+func DeleteSmartFolder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if err := database.DeleteSmartFolder(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到智能文件夹"})
+			return
+		}
+		log.Printf("删除智能文件夹失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除智能文件夹失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "智能文件夹已删除"})
+}
+
+// GetSmartFolderPasswords 按智能文件夹的搜索语句列出精确匹配的条目（不含模糊匹配，与文件夹显示的数量一致）。
+// 与条目列表相同，支持筛选、sort 排序、limit/cursor 分页和 fields 参数，总数在 X-Total-Count 响应头中
+func GetSmartFolderPasswords(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	folder, err := database.GetSmartFolderByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到智能文件夹"})
+			return
+		}
+		log.Printf("获取智能文件夹失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取智能文件夹失败"})
+		return
+	}
+
+	query, ok := parseSearchQuery(c, folder.Query)
+	if !ok {
+		return
+	}
+	filter, ok := parsePasswordFilter(c)
+	if !ok {
+		return
+	}
+	filter.Search = query
+	limit, cursor, ok := parsePageParams(c)
+	if !ok {
+		return
+	}
+	fields, ok := parseFieldsParam(c)
+	if !ok {
+		return
+	}
+
+	page, err := database.ListPasswordsPage(filter, limit, cursor)
+	if err != nil {
+		if err == database.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalidCursorMessage})
+			return
+		}
+		log.Printf("获取智能文件夹条目失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取智能文件夹条目失败"})
+		return
+	}
+	respondPasswordPage(c, page, fields)
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/gin-gonic/gin"
+)
+
+// tagRequest 创建和重命名标签的请求数据
+type tagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// GetTags 获取所有标签及使用数量
+func GetTags(c *gin.Context) {
+	tags, err := database.GetAllTags()
+	if err != nil {
+		log.Printf("获取标签失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取标签失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// CreateTag 创建标签，条目也可以在保存时直接使用新标签
+func CreateTag(c *gin.Context) {
+	var req tagRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "标签名称不能为空"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	id, err := database.CreateTag(name)
+	if err != nil {
+		if err == database.ErrTagExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "标签已存在"})
+			return
+		}
+		log.Printf("创建标签失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建标签失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "name": name})
+}
+
+// RenameTag 重命名标签
+func RenameTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	var req tagRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "标签名称不能为空"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := database.RenameTag(id, name); err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到标签"})
+		case database.ErrTagExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "标签已存在"})
+		default:
+			log.Printf("重命名标签失败 ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "重命名标签失败"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "name": name})
+}
+
+// DeleteTag 删除标签，已使用该标签的条目不受影响
+func DeleteTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if err := database.DeleteTag(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到标签"})
+			return
+		}
+		log.Printf("删除标签失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除标签失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "标签已删除"})
+}
@@ -0,0 +1,348 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// 自定义字段支持的类型
+var fieldTypes = map[string]bool{
+	models.FieldTypeText:   true,
+	models.FieldTypeHidden: true,
+	models.FieldTypeURL:    true,
+	models.FieldTypeEmail:  true,
+	models.FieldTypeDate:   true,
+	models.FieldTypeTOTP:   true,
+}
+
+// GetTemplates 获取所有条目模板
+func GetTemplates(c *gin.Context) {
+	templates, err := database.GetTemplates()
+	if err != nil {
+		log.Printf("获取模板失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取模板失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate 获取单个条目模板
+func GetTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	template, err := database.GetTemplate(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到模板"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateTemplate 创建条目模板
+func CreateTemplate(c *gin.Context) {
+	var template models.Template
+	if err := c.ShouldBindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	if err := prepareTemplate(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := database.CreateTemplate(template)
+	if err != nil {
+		if err == database.ErrTemplateExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "模板名称已存在"})
+			return
+		}
+		log.Printf("创建模板失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建模板失败"})
+		return
+	}
+
+	created, err := database.GetTemplate(int(id))
+	if err != nil {
+		log.Printf("获取新建模板失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建模板失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateTemplate 修改条目模板，字段定义整体替换
+func UpdateTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	var template models.Template
+	if err := c.ShouldBindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	if err := prepareTemplate(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template.ID = id
+	if err := database.UpdateTemplate(template); err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到模板"})
+		case database.ErrTemplateExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "模板名称已存在"})
+		default:
+			log.Printf("修改模板失败 ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "修改模板失败"})
+		}
+		return
+	}
+
+	updated, err := database.GetTemplate(id)
+	if err != nil {
+		log.Printf("获取修改后的模板失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "修改模板失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteTemplate 删除条目模板，已从模板创建的条目不受影响
+func DeleteTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if err := database.DeleteTemplate(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到模板"})
+			return
+		}
+		log.Printf("删除模板失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除模板失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "模板已删除"})
+}
+
+// prepareTemplate 校验模板及其字段定义。
+// 敏感字段（隐藏、TOTP和密码）不能设置默认值，模板内容不加密，应使用生成规则
+func prepareTemplate(t *models.Template) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("模板名称不能为空")
+	}
+	t.Description = strings.TrimSpace(t.Description)
+	if t.ItemType == "" {
+		t.ItemType = models.ItemTypeLogin
+	}
+	if _, ok := models.ItemSchemas[t.ItemType]; !ok {
+		return fmt.Errorf("不支持的条目类型: %s", t.ItemType)
+	}
+	if t.Fields == nil {
+		t.Fields = []models.TemplateField{}
+	}
+
+	names := make(map[string]bool, len(t.Fields))
+	targets := make(map[string]bool)
+	for i := range t.Fields {
+		f := &t.Fields[i]
+		f.Name = strings.TrimSpace(f.Name)
+		if f.Name == "" {
+			return fmt.Errorf("模板字段名称不能为空")
+		}
+		key := strings.ToLower(f.Name)
+		if names[key] {
+			return fmt.Errorf("模板字段 %s 重复", f.Name)
+		}
+		names[key] = true
+
+		if f.Target == "" {
+			f.Target = models.TemplateTargetField
+		}
+		switch f.Target {
+		case models.TemplateTargetField:
+			if f.Type == "" {
+				f.Type = models.FieldTypeText
+			}
+			if !fieldTypes[f.Type] {
+				return fmt.Errorf("不支持的字段类型: %s", f.Type)
+			}
+		case models.TemplateTargetUsername, models.TemplateTargetPassword, models.TemplateTargetURI:
+			if targets[f.Target] {
+				return fmt.Errorf("模板中只能有一个写入 %s 的字段", f.Target)
+			}
+			targets[f.Target] = true
+			f.Type = ""
+		default:
+			return fmt.Errorf("不支持的字段位置: %s", f.Target)
+		}
+
+		secret := f.Target == models.TemplateTargetPassword ||
+			(f.Target == models.TemplateTargetField && models.CustomField{Type: f.Type}.IsSecret())
+		if f.Default != "" {
+			if secret {
+				return fmt.Errorf("敏感字段 %s 不能设置默认值，请使用生成规则", f.Name)
+			}
+			if err := validateTemplateValue(*f, f.Default); err != nil {
+				return err
+			}
+		}
+
+		if f.Generator != nil {
+			if !generatorAllowed(*f) {
+				return fmt.Errorf("字段 %s 不支持生成规则，生成的密码、PIN码和私钥只能写入条目密码或隐藏类型的字段", f.Name)
+			}
+			if err := utils.ValidateGeneratorRule(*f.Generator); err != nil {
+				return fmt.Errorf("字段 %s: %v", f.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// generatorAllowed 生成规则产生的都是敏感内容，只能写入条目密码或隐藏字段加密保存，
+// 写入用户名或文本字段会以明文保存，并出现在列表、搜索结果和全文索引中
+func generatorAllowed(f models.TemplateField) bool {
+	return f.Target == models.TemplateTargetPassword ||
+		(f.Target == models.TemplateTargetField && f.Type == models.FieldTypeHidden)
+}
+
+// validateTemplateValue 按字段写入的位置校验值的格式
+func validateTemplateValue(f models.TemplateField, value string) error {
+	switch f.Target {
+	case models.TemplateTargetField:
+		return validateField(models.CustomField{Name: f.Name, Type: f.Type, Value: value})
+	case models.TemplateTargetURI:
+		_, err := prepareURIs([]models.PasswordURI{{URI: value}})
+		return err
+	}
+	return nil
+}
+
+// CreateEntryFromTemplate 按模板创建条目：未填写的字段使用默认值或按生成规则生成，
+// 必填字段仍为空时返回400并列出缺少的字段
+func CreateEntryFromTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	template, err := database.GetTemplate(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到模板"})
+		return
+	}
+
+	var req models.TemplateEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	password, missing, err := applyTemplate(template, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少必填字段: " + strings.Join(missing, ", "), "missing": missing})
+		return
+	}
+
+	createEntry(c, password)
+}
+
+// applyTemplate 根据模板和提交的值生成条目，返回仍为空的必填字段
+func applyTemplate(t models.Template, req models.TemplateEntryRequest) (models.Password, []string, error) {
+	known := make(map[string]bool, len(t.Fields))
+	for _, f := range t.Fields {
+		known[f.Name] = true
+	}
+	for name := range req.Values {
+		if !known[name] {
+			return models.Password{}, nil, fmt.Errorf("模板中没有字段 %s", name)
+		}
+	}
+
+	p := models.Password{
+		ItemType: t.ItemType,
+		Name:     req.Name,
+		Notes:    req.Notes,
+		ItemData: req.ItemData,
+		FolderID: req.FolderID,
+		Tags:     req.Tags,
+		Fields:   []models.CustomField{},
+		URIs:     []models.PasswordURI{},
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		p.Name = t.Name
+	}
+
+	missing := []string{}
+	for _, f := range t.Fields {
+		value := req.Values[f.Name]
+		if value == "" {
+			value = f.Default
+		}
+		if value == "" && f.Generator != nil {
+			// 不依赖保存模板时的校验，生成的敏感内容不能写入明文字段
+			if !generatorAllowed(f) {
+				return p, nil, fmt.Errorf("模板字段 %s 的生成规则只能用于密码或隐藏类型的字段，请修改模板", f.Name)
+			}
+			generated, err := utils.Generate(*f.Generator)
+			if err != nil {
+				return p, nil, fmt.Errorf("生成字段 %s 失败: %v", f.Name, err)
+			}
+			value = generated
+		}
+		if value == "" {
+			if f.Required {
+				missing = append(missing, f.Name)
+			}
+			continue
+		}
+		if err := validateTemplateValue(f, value); err != nil {
+			return p, nil, err
+		}
+
+		switch f.Target {
+		case models.TemplateTargetUsername:
+			p.Username = value
+		case models.TemplateTargetPassword:
+			p.Password = value
+		case models.TemplateTargetURI:
+			p.URIs = append(p.URIs, models.PasswordURI{URI: value})
+		default:
+			p.Fields = append(p.Fields, models.CustomField{Name: f.Name, Type: f.Type, Value: value})
+		}
+	}
+	return p, missing, nil
+}
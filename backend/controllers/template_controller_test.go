@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/007Secret/007Password/models"
+)
+
+func TestPrepareTemplateGeneratorPlacement(t *testing.T) {
+	password := &models.GeneratorRule{Kind: models.GeneratorPassword, Length: 16}
+	tests := []struct {
+		name  string
+		field models.TemplateField
+		valid bool
+	}{
+		{"password target", models.TemplateField{Name: "pw", Target: models.TemplateTargetPassword, Generator: password}, true},
+		{"hidden field", models.TemplateField{Name: "pin", Type: models.FieldTypeHidden, Generator: &models.GeneratorRule{Kind: models.GeneratorPIN}}, true},
+		{"hidden ssh key", models.TemplateField{Name: "key", Type: models.FieldTypeHidden, Generator: &models.GeneratorRule{Kind: models.GeneratorSSHEd25519}}, true},
+		{"text field", models.TemplateField{Name: "pin", Type: models.FieldTypeText, Generator: password}, false},
+		{"unset type", models.TemplateField{Name: "pin", Generator: password}, false},
+		{"totp field", models.TemplateField{Name: "otp", Type: models.FieldTypeTOTP, Generator: password}, false},
+		{"username target", models.TemplateField{Name: "user", Target: models.TemplateTargetUsername, Generator: password}, false},
+		{"uri target", models.TemplateField{Name: "site", Target: models.TemplateTargetURI, Generator: password}, false},
+		{"invalid rule", models.TemplateField{Name: "pw", Target: models.TemplateTargetPassword, Generator: &models.GeneratorRule{Kind: "uuid"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := models.Template{Name: "t", Fields: []models.TemplateField{tt.field}}
+			err := prepareTemplate(&tmpl)
+			if (err == nil) != tt.valid {
+				t.Errorf("prepareTemplate() error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+// 未经prepareTemplate校验的模板也不能把生成的内容写入明文字段
+func TestApplyTemplateRejectsPlaintextGenerator(t *testing.T) {
+	tmpl := models.Template{
+		Name:     "pin",
+		ItemType: models.ItemTypeLogin,
+		Fields: []models.TemplateField{
+			{Name: "pin", Type: models.FieldTypeText, Target: models.TemplateTargetField, Generator: &models.GeneratorRule{Kind: models.GeneratorPIN}},
+		},
+	}
+	if _, _, err := applyTemplate(tmpl, models.TemplateEntryRequest{}); err == nil {
+		t.Error("applyTemplate() generated a value into a text field")
+	}
+}
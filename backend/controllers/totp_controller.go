@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// 粘贴的二维码图片解码后的大小上限
+const maxQRImageSize = 5 << 20
+
+type totpParseRequest struct {
+	Value string `json:"value"`
+}
+
+// isImageDataURL 判断是否为 data:image/...;base64, 形式的图片
+func isImageDataURL(value string) bool {
+	return strings.HasPrefix(strings.ToLower(value), "data:image/")
+}
+
+// decodeQRDataURL 识别data URL图片中的二维码，返回其中的文本
+func decodeQRDataURL(value string) (string, error) {
+	header, payload, ok := strings.Cut(value, ",")
+	if !ok || !strings.HasSuffix(strings.ToLower(header), ";base64") {
+		return "", errors.New("图片须为base64编码的data URL")
+	}
+	if base64.StdEncoding.DecodedLen(len(payload)) > maxQRImageSize {
+		return "", errors.New("二维码图片过大")
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", errors.New("图片须为base64编码的data URL")
+	}
+	return utils.DecodeQRImage(data)
+}
+
+// otpKeyInfo 返回密钥参数，不包含密钥本身
+func otpKeyInfo(k *utils.OTPKey) gin.H {
+	info := gin.H{
+		"type":      k.Type,
+		"issuer":    k.Issuer,
+		"account":   k.Account,
+		"algorithm": k.Algorithm,
+		"digits":    k.Digits,
+		"steam":     k.Steam,
+	}
+	if k.Type == utils.OTPTypeHOTP {
+		info["counter"] = k.Counter
+	} else {
+		info["period"] = k.Period
+	}
+	return info
+}
+
+// ParseTOTP 解析otpauth链接、Base32密钥或二维码图片（data URL），
+// 返回规范化的链接和参数，供客户端保存到TOTP字段前预览
+func ParseTOTP(c *gin.Context) {
+	var req totpParseRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Value) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请提供otpauth链接、密钥或二维码图片"})
+		return
+	}
+
+	value := req.Value
+	if isImageDataURL(value) {
+		text, err := decodeQRDataURL(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		value = text
+	}
+
+	key, err := utils.ParseOTP(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	info := otpKeyInfo(key)
+	info["uri"] = key.URI()
+	c.JSON(http.StatusOK, info)
+}
+
+// findTOTPField 查找条目的TOTP字段，fieldID为0时取第一个非空的TOTP字段
+func findTOTPField(p models.Password, fieldID int) (models.CustomField, bool) {
+	for _, f := range p.Fields {
+		if f.Type != models.FieldTypeTOTP || f.Value == "" {
+			continue
+		}
+		if fieldID == 0 || f.ID == fieldID {
+			return f, true
+		}
+	}
+	return models.CustomField{}, false
+}
+
+// GetTOTPCode 生成条目TOTP字段的当前验证码及剩余秒数，不修改条目。
+// HOTP验证码每生成一次计数器就要加一，须通过 POST 请求生成（见NextOTPCode），
+// 避免预取、重试或缓存的GET请求使计数器与服务端不同步
+func GetTOTPCode(c *gin.Context) {
+	respondOTPCode(c, false)
+}
+
+// NextOTPCode 生成验证码，HOTP使用当前计数器生成验证码后将计数器加一并保存；TOTP与GET相同
+func NextOTPCode(c *gin.Context) {
+	respondOTPCode(c, true)
+}
+
+// respondOTPCode 生成条目TOTP/HOTP字段的验证码，advance为false时不生成HOTP验证码
+func respondOTPCode(c *gin.Context, advance bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	fieldID := 0
+	if v := c.Query("fieldId"); v != "" {
+		fieldID, err = strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的字段ID"})
+			return
+		}
+	}
+
+	password, err := database.GetPasswordByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+		return
+	}
+
+	if !requireRevealAccess(c, password) {
+		return
+	}
+
+	field, ok := findTOTPField(password, fieldID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "条目未配置TOTP"})
+		return
+	}
+
+	secret, err := utils.DecryptPassword(field.Value)
+	if err != nil {
+		log.Printf("解密TOTP字段失败 ID=%d 字段ID=%d: %v", id, field.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "解密TOTP密钥失败"})
+		return
+	}
+	key, err := utils.ParseOTP(secret)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("TOTP密钥无效: %v", err)})
+		return
+	}
+
+	resp := otpKeyInfo(key)
+	resp["fieldId"] = field.ID
+
+	if key.Type == utils.OTPTypeHOTP {
+		if !advance {
+			c.Header("Allow", "POST")
+			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "HOTP验证码会使计数器加一，请使用POST请求生成", "code": "HOTP_REQUIRES_POST"})
+			return
+		}
+		code, err := key.Code(key.Counter)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("TOTP密钥无效: %v", err)})
+			return
+		}
+
+		// 计数器写回字段，只有值未被其他请求修改时才生效
+		key.Counter++
+		encrypted, err := utils.EncryptPassword(key.URI())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "加密HOTP密钥失败"})
+			return
+		}
+		if err := database.UpdateFieldValue(field.ID, field.Value, encrypted); err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusConflict, gin.H{"error": "HOTP计数器已被其他请求更新，请重试"})
+				return
+			}
+			log.Printf("保存HOTP计数器失败 ID=%d 字段ID=%d: %v", id, field.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "保存HOTP计数器失败"})
+			return
+		}
+		resp["code"] = code
+		resp["counter"] = key.Counter
+	} else {
+		code, remaining, err := key.TOTPCode(time.Now())
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("TOTP密钥无效: %v", err)})
+			return
+		}
+		resp["code"] = code
+		resp["secondsRemaining"] = remaining
+	}
+
+	recordAudit(c, models.AuditActionTOTP, id, customFieldPrefix+strconv.Itoa(field.ID))
+	recordUse(id)
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/middleware"
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetItemTypes 获取所有条目类型及其字段定义，供客户端生成表单
+func GetItemTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, models.ItemSchemas)
+}
+
+// ExportPasswords 导出所有条目（包括各类型的敏感字段明文），需要二次验证并记录审计日志
+func ExportPasswords(c *gin.Context) {
+	if !middleware.HasValidStepUp(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "导出数据需要重新验证主密码", "code": "STEP_UP_REQUIRED"})
+		return
+	}
+
+	passwords, err := database.GetAllPasswords()
+	if err != nil {
+		log.Printf("导出时获取条目失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "导出失败"})
+		return
+	}
+
+	folderPaths, err := database.FolderPaths()
+	if err != nil {
+		log.Printf("导出时获取文件夹失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "导出失败"})
+		return
+	}
+
+	export := models.ExportFile{
+		Version:    models.ExportVersion,
+		ExportedAt: time.Now(),
+		Items:      make([]models.ExportItem, 0, len(passwords)),
+	}
+	for _, p := range passwords {
+		decryptForResponse(c, &p)
+		item := models.ExportItem{Password: p}
+		if p.FolderID != nil {
+			item.FolderPath = folderPaths[*p.FolderID]
+		}
+		export.Items = append(export.Items, item)
+	}
+
+	recordAudit(c, models.AuditActionExport, 0, "all")
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="007password-export-%s.json"`, time.Now().Format("20060102")))
+	c.JSON(http.StatusOK, export)
+}
+
+// ImportPasswords 导入导出接口生成的文件，所有条目校验通过后在一个事务中写入
+func ImportPasswords(c *gin.Context) {
+	var file models.ExportFile
+	if err := c.ShouldBindJSON(&file); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的导入文件"})
+		return
+	}
+	if file.Version < 1 || file.Version > models.ExportVersion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("不支持的导入文件版本: %d", file.Version)})
+		return
+	}
+
+	for i := range file.Items {
+		p := &file.Items[i].Password
+		// 版本1的导出文件只有一个网址
+		if len(p.URIs) == 0 && file.Items[i].Website != "" {
+			p.URIs = []models.PasswordURI{{URI: file.Items[i].Website, Match: models.URIMatchDomain}}
+		}
+		if err := prepareImportItem(p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("第 %d 个条目: %v", i+1, err), "index": i})
+			return
+		}
+	}
+
+	imported, err := database.ImportPasswords(file.Items, rewriteImportRefs)
+	if err != nil {
+		log.Printf("导入条目失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "导入失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "导入成功", "imported": imported})
+}
+
+// prepareImportItem 校验导入的条目并加密敏感内容
+func prepareImportItem(p *models.Password) error {
+	if err := prepareItem(p, nil); err != nil {
+		return err
+	}
+	if err := prepareExpiry(p); err != nil {
+		return err
+	}
+
+	fields, err := prepareFields(p.Fields, nil)
+	if err != nil {
+		return err
+	}
+	p.Fields = fields
+
+	uris, err := prepareURIs(p.URIs)
+	if err != nil {
+		return err
+	}
+	p.URIs = uris
+
+	p.Strength = utils.EntryStrengthScore(p.Password)
+	if p.Password != "" {
+		encrypted, err := utils.EncryptPassword(p.Password)
+		if err != nil {
+			return fmt.Errorf("加密密码失败")
+		}
+		p.Password = encrypted
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrash 获取回收站中的条目，密码与列表接口一样只返回占位值
+func GetTrash(c *gin.Context) {
+	passwords, err := database.GetTrashedPasswords()
+	if err != nil {
+		log.Printf("获取回收站失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取回收站失败"})
+		return
+	}
+
+	for i := range passwords {
+		maskPasswordEntry(&passwords[i])
+	}
+
+	c.JSON(http.StatusOK, passwords)
+}
+
+// RestoreTrash 将条目从回收站恢复
+func RestoreTrash(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if err := database.RestoreTrashedPassword(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "回收站中没有该条目"})
+			return
+		}
+		log.Printf("恢复条目失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "恢复条目失败"})
+		return
+	}
+
+	restoredPassword, err := database.GetPasswordByID(id)
+	if err == nil {
+		maskPasswordEntry(&restoredPassword)
+	}
+
+	c.JSON(http.StatusOK, restoredPassword)
+}
+
+// PurgeTrash 彻底删除回收站中的条目，删除后无法恢复
+func PurgeTrash(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if err := database.PurgeTrashedPassword(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "回收站中没有该条目"})
+			return
+		}
+		log.Printf("彻底删除条目失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "彻底删除条目失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "条目已彻底删除"})
+}
+
+// EmptyTrash 清空回收站
+func EmptyTrash(c *gin.Context) {
+	purged, err := database.EmptyTrash()
+	if err != nil {
+		log.Printf("清空回收站失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "清空回收站失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "回收站已清空", "purged": purged})
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+	"github.com/007Secret/007Password/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// prepareURIs 校验条目网址，去掉空网址，未指定匹配规则时按基础域名匹配
+func prepareURIs(uris []models.PasswordURI) ([]models.PasswordURI, error) {
+	prepared := make([]models.PasswordURI, 0, len(uris))
+	for _, u := range uris {
+		u.ID = 0
+		u.URI = strings.TrimSpace(u.URI)
+		if u.URI == "" {
+			continue
+		}
+		if u.Match == "" {
+			u.Match = models.URIMatchDomain
+		}
+
+		switch u.Match {
+		case models.URIMatchDomain, models.URIMatchHost:
+			parsed, err := utils.ParseLooseURL(u.URI)
+			if err != nil || parsed.Hostname() == "" {
+				return nil, fmt.Errorf("网址 %s 无效", u.URI)
+			}
+		case models.URIMatchRegex:
+			if _, err := regexp.Compile(u.URI); err != nil {
+				return nil, fmt.Errorf("网址 %s 不是有效的正则表达式", u.URI)
+			}
+		case models.URIMatchStartsWith, models.URIMatchNever:
+		default:
+			return nil, fmt.Errorf("不支持的网址匹配规则: %s", u.Match)
+		}
+
+		prepared = append(prepared, u)
+	}
+	return prepared, nil
+}
+
+// matchesURL 判断条目是否有网址匹配目标网址
+func matchesURL(p models.Password, target string) bool {
+	for _, u := range p.URIs {
+		if utils.MatchURI(u.URI, u.Match, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPasswords 按网址查找条目，每个条目的网址按各自的匹配规则与目标网址比较。
+// 与列表接口一样只返回元数据
+func MatchPasswords(c *gin.Context) {
+	target := strings.TrimSpace(c.Query("url"))
+	if target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请提供要匹配的网址"})
+		return
+	}
+
+	passwords, err := database.GetAllPasswords()
+	if err != nil {
+		log.Printf("按网址匹配条目失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "匹配条目失败"})
+		return
+	}
+
+	matched := make([]models.Password, 0)
+	for _, p := range passwords {
+		if matchesURL(p, target) {
+			maskPasswordEntry(&p)
+			matched = append(matched, p)
+		}
+	}
+
+	c.JSON(http.StatusOK, matched)
+}
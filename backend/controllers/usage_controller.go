@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/gin-gonic/gin"
+)
+
+type favoriteRequest struct {
+	Favorite bool `json:"favorite"`
+}
+
+// recordUse 更新条目的使用统计，失败时只记录日志，不影响请求结果
+func recordUse(id int) {
+	if err := database.RecordPasswordUse(id); err != nil {
+		log.Printf("记录条目使用失败 ID=%d: %v", id, err)
+	}
+}
+
+// SetFavorite 收藏或取消收藏条目
+func SetFavorite(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	var req favoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	if err := database.SetPasswordFavorite(id, req.Favorite); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+			return
+		}
+		log.Printf("设置收藏失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "设置收藏失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "favorite": req.Favorite})
+}
+
+// RecordUse 记录客户端对条目的使用，如复制用户名或密码。
+// 查看密码和获取验证码时服务端会自动记录，无需调用
+func RecordUse(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if err := database.RecordPasswordUse(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未找到密码"})
+			return
+		}
+		log.Printf("记录条目使用失败 ID=%d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "记录使用失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已记录"})
+}
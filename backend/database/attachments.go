@@ -0,0 +1,106 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// 附件目录，位于数据库所在目录下
+const attachmentFolder = "attachments"
+
+// AttachmentDir 获取附件目录路径
+func AttachmentDir() string {
+	return filepath.Join(GetDBFolder(), attachmentFolder)
+}
+
+// AttachmentPath 获取附件加密文件的路径
+func AttachmentPath(blobID string) string {
+	return filepath.Join(AttachmentDir(), blobID)
+}
+
+// NewBlobID 生成附件文件的随机名称，文件名不包含任何条目信息
+func NewBlobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+const attachmentColumns = "id, password_id, file_name, content_type, size, blob_id, created_at"
+
+func scanAttachment(row rowScanner) (models.Attachment, error) {
+	var a models.Attachment
+	err := row.Scan(&a.ID, &a.PasswordID, &a.FileName, &a.ContentType, &a.Size, &a.BlobID, &a.CreatedAt)
+	return a, err
+}
+
+// CreateAttachment 保存附件元数据
+func CreateAttachment(a models.Attachment) (int64, error) {
+	result, err := DB.Exec(
+		"INSERT INTO attachments (password_id, file_name, content_type, size, blob_id, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		a.PasswordID, a.FileName, a.ContentType, a.Size, a.BlobID, time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetAttachments 获取条目的所有附件
+func GetAttachments(passwordID int) ([]models.Attachment, error) {
+	rows, err := DB.Query("SELECT "+attachmentColumns+" FROM attachments WHERE password_id = ? ORDER BY id", passwordID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []models.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// GetAttachment 获取条目的指定附件
+func GetAttachment(passwordID, id int) (models.Attachment, error) {
+	return scanAttachment(DB.QueryRow("SELECT "+attachmentColumns+" FROM attachments WHERE id = ? AND password_id = ?", id, passwordID))
+}
+
+// GetAttachmentUsage 获取所有附件（包括回收站条目的附件）占用的总字节数
+func GetAttachmentUsage() (int64, error) {
+	var total int64
+	err := DB.QueryRow("SELECT COALESCE(SUM(size), 0) FROM attachments").Scan(&total)
+	return total, err
+}
+
+// DeleteAttachment 删除附件元数据及加密文件
+func DeleteAttachment(passwordID, id int) error {
+	a, err := GetAttachment(passwordID, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := DB.Exec("DELETE FROM attachments WHERE id = ?", id); err != nil {
+		return err
+	}
+	RemoveAttachmentFile(a.BlobID)
+	return nil
+}
+
+// RemoveAttachmentFile 删除附件的加密文件，文件不存在时忽略
+func RemoveAttachmentFile(blobID string) {
+	if err := os.Remove(AttachmentPath(blobID)); err != nil && !os.IsNotExist(err) {
+		log.Printf("删除附件文件失败 %s: %v", blobID, err)
+	}
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// AddAuditLog 记录一条审计日志
+func AddAuditLog(entry models.AuditLog) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	_, err := DB.Exec(
+		"INSERT INTO audit_log (action, password_id, field, client_ip, user_agent, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		entry.Action, entry.PasswordID, entry.Field, entry.ClientIP, entry.UserAgent, entry.CreatedAt,
+	)
+	return err
+}
+
+// GetAuditLogs 获取审计日志，passwordID为0时返回所有条目的记录
+func GetAuditLogs(passwordID int, limit int) ([]models.AuditLog, error) {
+	query := "SELECT id, action, password_id, field, client_ip, user_agent, created_at FROM audit_log"
+	var args []interface{}
+	if passwordID > 0 {
+		query += " WHERE password_id = ?"
+		args = append(args, passwordID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []models.AuditLog{}
+	for rows.Next() {
+		var l models.AuditLog
+		if err := rows.Scan(&l.ID, &l.Action, &l.PasswordID, &l.Field, &l.ClientIP, &l.UserAgent, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+
+	return logs, rows.Err()
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// 批量操作的类型
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+	BatchTag    = "tag"
+	BatchMove   = "move"
+)
+
+// ErrBatchAborted 整体模式下因其他操作失败而未执行或已回滚的操作
+var ErrBatchAborted = errors.New("batch aborted")
+
+// BatchOp 批量操作中的一项，内容需已由调用方校验并加密
+type BatchOp struct {
+	Kind     string
+	ID       int             // update、delete、tag、move的目标条目
+	Revision int             // 不为0时只有条目当前修订号与之相同才执行，0表示客户端要求不检查
+	Password models.Password // create和update保存的内容
+	AddTags  []string        // tag添加的标签
+	DelTags  []string        // tag移除的标签
+	FolderID *int            // move的目标文件夹，nil表示移出文件夹
+}
+
+// BatchOutcome 单项批量操作的执行结果，Err为nil表示成功
+type BatchOutcome struct {
+	ID  int
+	Err error
+}
+
+// ExecBatch 在一个事务中依次执行批量操作，prepared中不为nil的错误表示该项在校验时已失败。
+// atomic为true时任何一项失败都回滚全部操作，失败项之外的结果为ErrBatchAborted；
+// 否则每项操作在各自的保存点中执行，失败的操作单独回滚，其余操作照常提交。
+// 返回值中的error只表示事务本身失败
+func ExecBatch(ops []BatchOp, prepared []error, atomic bool) ([]BatchOutcome, error) {
+	outcomes := make([]BatchOutcome, len(ops))
+	for i, op := range ops {
+		outcomes[i] = BatchOutcome{ID: op.ID, Err: prepared[i]}
+	}
+
+	if atomic {
+		for _, o := range outcomes {
+			if o.Err != nil {
+				return abortBatch(ops, outcomes), nil
+			}
+		}
+	}
+
+	failed := false
+	err := withTx(func(tx *sql.Tx) error {
+		for i, op := range ops {
+			if outcomes[i].Err != nil {
+				continue
+			}
+
+			if !atomic {
+				if _, err := tx.Exec("SAVEPOINT batch_op"); err != nil {
+					return err
+				}
+			}
+			id, err := execBatchOp(tx, op)
+			outcomes[i].ID = id
+			outcomes[i].Err = err
+
+			if atomic {
+				if err != nil {
+					failed = true
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				if _, err := tx.Exec("ROLLBACK TO batch_op"); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.Exec("RELEASE batch_op"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if failed {
+		return abortBatch(ops, outcomes), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return outcomes, nil
+}
+
+// abortBatch 将未失败的操作标记为ErrBatchAborted，新建条目的ID随回滚失效
+func abortBatch(ops []BatchOp, outcomes []BatchOutcome) []BatchOutcome {
+	for i := range outcomes {
+		if ops[i].Kind == BatchCreate {
+			outcomes[i].ID = 0
+		}
+		if outcomes[i].Err == nil {
+			outcomes[i].Err = ErrBatchAborted
+		}
+	}
+	return outcomes
+}
+
+// execBatchOp 执行单项批量操作，返回操作的条目ID
+func execBatchOp(tx *sql.Tx, op BatchOp) (int, error) {
+	switch op.Kind {
+	case BatchCreate:
+		id, err := createPassword(tx, op.Password)
+		return int(id), err
+	case BatchUpdate:
+		op.Password.ID = op.ID
+		op.Password.Revision = op.Revision
+		return op.ID, updatePasswordTx(tx, op.Password)
+	case BatchDelete:
+		return op.ID, deletePasswordTx(tx, op.ID, op.Revision)
+	case BatchTag:
+		return op.ID, tagPasswordTx(tx, op.ID, op.Revision, op.AddTags, op.DelTags)
+	case BatchMove:
+		return op.ID, movePasswordTx(tx, op.ID, op.Revision, op.FolderID)
+	}
+	return op.ID, fmt.Errorf("unknown batch operation: %s", op.Kind)
+}
+
+// tagPasswordTx 为条目添加和移除标签，不影响条目的其他标签
+func tagPasswordTx(q querier, id, revision int, add, remove []string) error {
+	if err := bumpRevision(q, id, revision); err != nil {
+		return err
+	}
+
+	tags, err := passwordTagNames(q, id)
+	if err != nil {
+		return err
+	}
+	removed := make(map[string]bool, len(remove))
+	for _, name := range normalizeTags(remove) {
+		removed[name] = true
+	}
+	kept := make([]string, 0, len(tags)+len(add))
+	for _, name := range tags {
+		if !removed[name] {
+			kept = append(kept, name)
+		}
+	}
+	if err := setPasswordTags(q, id, append(kept, add...)); err != nil {
+		return err
+	}
+	_, err = q.Exec("UPDATE passwords SET updated_at = ? WHERE id = ?", time.Now(), id)
+	return err
+}
+
+// passwordTagNames 获取条目当前的标签名称
+func passwordTagNames(q querier, id int) ([]string, error) {
+	rows, err := q.Query("SELECT t.name FROM password_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.password_id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// movePasswordTx 将条目移动到指定文件夹，folderID为nil时移出文件夹
+func movePasswordTx(q querier, id, revision int, folderID *int) error {
+	if err := bumpRevision(q, id, revision); err != nil {
+		return err
+	}
+	_, err := q.Exec("UPDATE passwords SET folder_id = ?, updated_at = ? WHERE id = ?", folderID, time.Now(), id)
+	return err
+}
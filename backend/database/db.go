@@ -2,13 +2,15 @@ package database
 
 import (
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	// 使用mutecomm/go-sqlcipher进行SQLite加密
@@ -41,7 +43,9 @@ func InitDB() error {
 
 	// 数据库连接字符串
 	dbPath := filepath.Join(dbFolder, dbFile)
-	connectionString := fmt.Sprintf("%s?_foreign_keys=on", dbPath)
+	_, statErr := os.Stat(dbPath)
+	fileExists := statErr == nil
+	connectionString := fmt.Sprintf("%s?_foreign_keys=on&_secure_delete=on", dbPath)
 	log.Printf("使用数据库路径: %s", dbPath)
 
 	// 打开数据库连接
@@ -58,13 +62,18 @@ func InitDB() error {
 		return err
 	}
 
-	// 初始化数据库表
-	err = initTables()
+	// 执行数据库迁移，创建或升级表结构
+	err = runMigrations(dbPath, fileExists)
 	if err != nil {
-		log.Printf("Failed to initialize tables: %v", err)
+		log.Printf("Failed to migrate database: %v", err)
 		return err
 	}
 
+	// 全文索引创建失败不影响使用，搜索退回到LIKE匹配
+	if err := ensureSearchIndex(); err != nil {
+		log.Printf("创建全文索引失败: %v", err)
+	}
+
 	log.Println("数据库初始化成功")
 	return nil
 }
@@ -140,13 +149,10 @@ func InitDBWithKey(key string) error {
 		}
 	}
 
-	// 使用SQLCipher文档推荐的DSN格式
-	dsn := fmt.Sprintf("%s?_pragma_key=%s&_pragma_cipher_page_size=4096&_foreign_keys=on&_journal_mode=WAL",
-		dbPath, url.QueryEscape(key))
 	log.Printf("正在连接数据库，使用DSN参数设置密钥")
 
 	// 打开连接
-	DB, err = sql.Open("sqlite3", dsn)
+	DB, err = sql.Open("sqlite3", encryptedDSN(dbPath, key))
 	if err != nil {
 		return fmt.Errorf("无法打开数据库连接: %w", err)
 	}
@@ -167,16 +173,17 @@ func InitDBWithKey(key string) error {
 
 	log.Printf("成功连接到SQLite (版本 %s)", version)
 
-	// 如果是新数据库，初始化表结构
-	if !fileExists {
-		log.Printf("初始化新数据库表结构")
-		err = initTables()
-		if err != nil {
-			DB.Close()
-			DB = nil
-			return fmt.Errorf("创建数据库表失败: %w", err)
-		}
-		log.Printf("成功创建数据库表")
+	// 解锁后执行数据库迁移：新数据库创建全部表结构，已有数据库只执行未完成的迁移
+	err = runMigrations(dbPath, fileExists)
+	if err != nil {
+		DB.Close()
+		DB = nil
+		return fmt.Errorf("数据库迁移失败: %w", err)
+	}
+
+	// 全文索引创建失败不影响使用，搜索退回到LIKE匹配
+	if err := ensureSearchIndex(); err != nil {
+		log.Printf("创建全文索引失败: %v", err)
 	}
 
 	// 进行最终的ping测试
@@ -191,6 +198,13 @@ func InitDBWithKey(key string) error {
 	return nil
 }
 
+// encryptedDSN 生成带加密密钥的连接字符串，使用SQLCipher文档推荐的DSN格式，
+// 开启secure_delete使删除的内容被清零
+func encryptedDSN(dbPath, key string) string {
+	return fmt.Sprintf("%s?_pragma_key=%s&_pragma_cipher_page_size=4096&_foreign_keys=on&_journal_mode=WAL&_secure_delete=on",
+		dbPath, url.QueryEscape(key))
+}
+
 // 辅助函数：掩盖字符串，用于安全日志记录
 func maskString(s string) string {
 	if len(s) <= 2 {
@@ -217,45 +231,77 @@ func createDataDirIfNotExist() error {
 	return os.MkdirAll(dataPath, 0755)
 }
 
-// 初始化数据库表
-func initTables() error {
-	// 创建settings表
-	_, err := DB.Exec(`
-		CREATE TABLE IF NOT EXISTS settings (
-			key TEXT PRIMARY KEY,
-			value TEXT NOT NULL
-		)
-	`)
+// InitTables 公开初始化表结构的函数，执行所有待执行的迁移
+func InitTables() error {
+	return runMigrations(GetDBPath(), true)
+}
+
+// passwords表查询时使用的列，顺序需与scanPassword保持一致
+const passwordColumns = "id, item_type, name, username, phone, password, notes, item_data, item_search, protected, favorite, last_used_at, use_count, expires_at, rotation_days, password_changed_at, revision, strength, folder_id, created_at, updated_at, deleted_at"
+
+// rowScanner 同时适用于 *sql.Row 和 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// querier 同时适用于 *sql.DB 和 *sql.Tx，便于同一段SQL在事务内外复用
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// withTx 在事务中执行fn，fn返回错误时回滚
+func withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := DB.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// 创建passwords表
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS passwords (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			username TEXT,
-			phone TEXT,
-			password TEXT NOT NULL,
-			website TEXT,
-			auth_logins TEXT,
-			notes TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	return err
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// InitTables 公开初始化表结构的函数
-func InitTables() error {
-	return initTables()
+// scanPassword 从查询结果中读取一条密码记录
+func scanPassword(row rowScanner) (models.Password, error) {
+	var p models.Password
+	var folderID, strength sql.NullInt64
+	var deletedAt, lastUsedAt, expiresAt, changedAt sql.NullTime
+
+	err := row.Scan(&p.ID, &p.ItemType, &p.Name, &p.Username, &p.Phone, &p.Password, &p.Notes, &p.ItemCipher, &p.ItemSearch, &p.Protected, &p.Favorite, &lastUsedAt, &p.UseCount, &expiresAt, &p.RotationDays, &changedAt, &p.Revision, &strength, &folderID, &p.CreatedAt, &p.UpdatedAt, &deletedAt)
+	if err != nil {
+		return p, err
+	}
+	if folderID.Valid {
+		id := int(folderID.Int64)
+		p.FolderID = &id
+	}
+	if strength.Valid {
+		score := int(strength.Int64)
+		p.Strength = &score
+	}
+	if deletedAt.Valid {
+		p.DeletedAt = &deletedAt.Time
+	}
+	if lastUsedAt.Valid {
+		p.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		p.ExpiresAt = &expiresAt.Time
+	}
+	if changedAt.Valid {
+		p.PasswordChangedAt = &changedAt.Time
+	}
+
+	return p, nil
 }
 
-// GetAllPasswords 获取所有密码
-func GetAllPasswords() ([]models.Password, error) {
-	rows, err := DB.Query("SELECT id, name, username, phone, password, website, auth_logins, notes, created_at, updated_at FROM passwords")
+// queryPasswords 执行查询并读取所有密码记录及其标签
+func queryPasswords(query string, args ...interface{}) ([]models.Password, error) {
+	rows, err := DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -263,20 +309,10 @@ func GetAllPasswords() ([]models.Password, error) {
 
 	var passwords []models.Password
 	for rows.Next() {
-		var p models.Password
-		var authLoginsJSON string
-		err := rows.Scan(&p.ID, &p.Name, &p.Username, &p.Phone, &p.Password, &p.Website, &authLoginsJSON, &p.Notes, &p.CreatedAt, &p.UpdatedAt)
+		p, err := scanPassword(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		// 解析JSON格式的AuthLogins
-		if authLoginsJSON != "" {
-			if err := json.Unmarshal([]byte(authLoginsJSON), &p.AuthLogins); err != nil {
-				log.Printf("Error unmarshaling auth_logins: %v", err)
-			}
-		}
-
 		passwords = append(passwords, p)
 	}
 
@@ -284,77 +320,349 @@ func GetAllPasswords() ([]models.Password, error) {
 		return nil, err
 	}
 
+	if err = attachRelations(passwords); err != nil {
+		return nil, err
+	}
 	return passwords, nil
 }
 
-// GetPasswordByID 通过ID获取密码
-func GetPasswordByID(id int) (models.Password, error) {
-	var p models.Password
-	var authLoginsJSON string
+// maxAttachIDs 读取关联数据时IN条件中条目ID数量的上限，超过时（如不分页获取全部条目）读取整张表
+const maxAttachIDs = 999
 
-	err := DB.QueryRow("SELECT id, name, username, phone, password, website, auth_logins, notes, created_at, updated_at FROM passwords WHERE id = ?", id).
-		Scan(&p.ID, &p.Name, &p.Username, &p.Phone, &p.Password, &p.Website, &authLoginsJSON, &p.Notes, &p.CreatedAt, &p.UpdatedAt)
+// passwordIDFilter 生成只读取这些条目关联数据的WHERE条件，column为关联表中的条目ID列
+func passwordIDFilter(column string, passwords []models.Password) (string, []interface{}) {
+	if len(passwords) > maxAttachIDs {
+		return "", nil
+	}
+	args := make([]interface{}, len(passwords))
+	for i, p := range passwords {
+		args[i] = p.ID
+	}
+	return " WHERE " + column + " IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ") + ")", args
+}
 
-	if err != nil {
-		return p, err
+// attachRelations 为条目填充标签、自定义字段、网址和登录方式
+func attachRelations(passwords []models.Password) error {
+	if err := attachTags(DB, passwords); err != nil {
+		return err
+	}
+	if err := attachFields(DB, passwords); err != nil {
+		return err
 	}
+	if err := attachURIs(DB, passwords); err != nil {
+		return err
+	}
+	return attachProviders(DB, passwords)
+}
+
+// GetAllPasswords 获取所有密码，不包含回收站中的条目
+func GetAllPasswords() ([]models.Password, error) {
+	return ListPasswords(PasswordFilter{})
+}
+
+// PasswordFilter 条目列表的筛选条件，零值表示不筛选
+type PasswordFilter struct {
+	// FolderID 只返回该文件夹中的条目
+	FolderID *int
+	// IncludeSubfolders 同时返回FolderID下级文件夹中的条目
+	IncludeSubfolders bool
+	// Unfiled 只返回不在任何文件夹中的条目
+	Unfiled bool
+	// Tags 只返回同时带有所有这些标签的条目
+	Tags []string
+	// ItemType 只返回该类型的条目
+	ItemType string
+	// Favorite 只返回收藏的条目
+	Favorite bool
+	// Search 只返回精确匹配该搜索语句的条目（不含模糊匹配），用于按智能文件夹分页列出条目
+	Search *SearchQuery
+	// Sort 排序方式，为空时按创建顺序，前面加 - 表示反向排序，如 -name
+	Sort string
+}
+
+// 列表排序方式
+const (
+	SortRecent   = "recent"   // 最近使用的在前，从未使用的排在最后
+	SortFrequent = "frequent" // 使用次数多的在前
+	SortName     = "name"     // 按名称
+	SortUpdated  = "updated"  // 最近修改的在前
+	SortCreated  = "created"  // 最近创建的在前
+)
+
+// ListPasswords 按筛选条件获取全部条目，不包含回收站中的条目
+func ListPasswords(filter PasswordFilter) ([]models.Password, error) {
+	page, err := ListPasswordsPage(filter, 0, "")
+	return page.Passwords, err
+}
+
+// filterConditions 将筛选条件（不包括Sort）转换为以 AND 开头的SQL条件
+func filterConditions(filter PasswordFilter) (string, []interface{}, error) {
+	var query string
+	var args []interface{}
 
-	// 解析JSON格式的AuthLogins
-	if authLoginsJSON != "" {
-		if err := json.Unmarshal([]byte(authLoginsJSON), &p.AuthLogins); err != nil {
-			log.Printf("Error unmarshaling auth_logins: %v", err)
+	switch {
+	case filter.Unfiled:
+		query += " AND folder_id IS NULL"
+	case filter.FolderID != nil && filter.IncludeSubfolders:
+		subtree, err := folderSubtree(DB, *filter.FolderID)
+		if err != nil {
+			return "", nil, err
+		}
+		query += " AND folder_id IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(subtree)), ", ") + ")"
+		for _, id := range subtree {
+			args = append(args, id)
 		}
+	case filter.FolderID != nil:
+		query += " AND folder_id = ?"
+		args = append(args, *filter.FolderID)
 	}
 
-	return p, nil
+	if filter.ItemType != "" {
+		query += " AND item_type = ?"
+		args = append(args, filter.ItemType)
+	}
+
+	if filter.Favorite {
+		query += " AND favorite = 1"
+	}
+
+	for _, tag := range normalizeTags(filter.Tags) {
+		query += " AND id IN (SELECT pt.password_id FROM password_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.name = ?)"
+		args = append(args, tag)
+	}
+
+	if filter.Search != nil {
+		cond, searchArgs := filter.Search.exactConditions()
+		query += cond
+		args = append(args, searchArgs...)
+	}
+	return query, args, nil
+}
+
+// GetPasswordByID 通过ID获取密码，回收站中的条目视为不存在
+func GetPasswordByID(id int) (models.Password, error) {
+	p, err := scanPassword(DB.QueryRow("SELECT "+passwordColumns+" FROM passwords WHERE id = ? AND deleted_at IS NULL", id))
+	if err != nil {
+		return p, err
+	}
+
+	entries := []models.Password{p}
+	if err = attachTags(DB, entries); err != nil {
+		return p, err
+	}
+	if err = attachFields(DB, entries); err != nil {
+		return p, err
+	}
+	if err = attachURIs(DB, entries); err != nil {
+		return p, err
+	}
+	err = attachProviders(DB, entries)
+	return entries[0], err
 }
 
 // CreatePassword 创建新密码
 func CreatePassword(p models.Password) (int64, error) {
+	var id int64
+	err := withTx(func(tx *sql.Tx) error {
+		var err error
+		id, err = createPassword(tx, p)
+		return err
+	})
+	return id, err
+}
+
+// createPassword 插入条目及其标签
+func createPassword(q querier, p models.Password) (int64, error) {
 	// 设置时间戳为当前时间
 	currentTime := time.Now()
 	p.CreatedAt = currentTime
 	p.UpdatedAt = currentTime
 
-	// 将AuthLogins转换为JSON
-	authLoginsJSON, err := json.Marshal(p.AuthLogins)
+	result, err := q.Exec(
+		"INSERT INTO passwords (item_type, name, username, phone, password, notes, item_data, item_search, protected, favorite, expires_at, rotation_days, password_changed_at, strength, folder_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		p.ItemType, p.Name, p.Username, p.Phone, p.Password, p.Notes, p.ItemCipher, p.ItemSearch, p.Protected, p.Favorite, p.ExpiresAt, p.RotationDays, currentTime, p.Strength, p.FolderID, p.CreatedAt, p.UpdatedAt,
+	)
 	if err != nil {
 		return 0, err
 	}
 
-	result, err := DB.Exec(
-		"INSERT INTO passwords (name, username, phone, password, website, auth_logins, notes, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
-		p.Name, p.Username, p.Phone, p.Password, p.Website, string(authLoginsJSON), p.Notes, p.CreatedAt, p.UpdatedAt,
-	)
+	id, err := result.LastInsertId()
 	if err != nil {
 		return 0, err
 	}
 
-	return result.LastInsertId()
+	if err := setPasswordTags(q, int(id), p.Tags); err != nil {
+		return 0, err
+	}
+	if err := setPasswordFields(q, int(id), p.Fields); err != nil {
+		return 0, err
+	}
+	if err := setPasswordURIs(q, int(id), p.URIs); err != nil {
+		return 0, err
+	}
+	if err := setPasswordProviders(q, int(id), p.Providers); err != nil {
+		return 0, err
+	}
+	if err := setPasswordRefs(q, int(id), p.Refs); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// ErrRevisionConflict 条目已被其他客户端修改，修订号与请求中的不一致
+var ErrRevisionConflict = errors.New("revision conflict")
+
+// bumpRevision 将条目修订号加一。revision不为0时只有当前修订号等于revision才生效，
+// 否则返回ErrRevisionConflict；条目不存在或已在回收站中时返回sql.ErrNoRows。
+// 在事务中应作为第一条语句执行，以便尽早取得写锁
+func bumpRevision(q querier, id, revision int) error {
+	query := "UPDATE passwords SET revision = revision + 1 WHERE id = ? AND deleted_at IS NULL"
+	args := []interface{}{id}
+	if revision != 0 {
+		query += " AND revision = ?"
+		args = append(args, revision)
+	}
+	result, err := q.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != sql.ErrNoRows || revision == 0 {
+		return err
+	}
+
+	var exists bool
+	if err := q.QueryRow("SELECT EXISTS(SELECT 1 FROM passwords WHERE id = ? AND deleted_at IS NULL)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrRevisionConflict
+	}
+	return sql.ErrNoRows
 }
 
-// UpdatePassword 更新密码
+// UpdatePassword 更新密码，密码发生变化时在同一事务中把旧值写入历史记录并更新密码修改时间。
+// p.Revision不为0时只有条目当前修订号与之相同才更新，否则返回ErrRevisionConflict
 func UpdatePassword(p models.Password) error {
+	return withTx(func(tx *sql.Tx) error {
+		return updatePasswordTx(tx, p)
+	})
+}
+
+// updatePasswordTx 在已有事务中执行UpdatePassword
+func updatePasswordTx(tx querier, p models.Password) error {
+	if err := bumpRevision(tx, p.ID, p.Revision); err != nil {
+		return err
+	}
+
+	var oldPassword string
+	if err := tx.QueryRow("SELECT password FROM passwords WHERE id = ?", p.ID).Scan(&oldPassword); err != nil {
+		return err
+	}
+
+	if oldPassword != "" && oldPassword != p.Password {
+		if err := addPasswordHistory(tx, p.ID, oldPassword, models.HistoryReasonUpdate); err != nil {
+			return err
+		}
+	}
+
+	if err := updatePassword(tx, p); err != nil {
+		return err
+	}
+	if oldPassword != p.Password {
+		if _, err := tx.Exec("UPDATE passwords SET password_changed_at = ? WHERE id = ?", time.Now(), p.ID); err != nil {
+			return err
+		}
+	}
+
+	// 未提交标签、自定义字段、网址或登录方式时保留原有内容
+	if p.Tags != nil {
+		if err := setPasswordTags(tx, p.ID, p.Tags); err != nil {
+			return err
+		}
+	}
+	if p.Fields != nil {
+		if err := setPasswordFields(tx, p.ID, p.Fields); err != nil {
+			return err
+		}
+	}
+	if p.URIs != nil {
+		if err := setPasswordURIs(tx, p.ID, p.URIs); err != nil {
+			return err
+		}
+	}
+	if p.Providers != nil {
+		if err := setPasswordProviders(tx, p.ID, p.Providers); err != nil {
+			return err
+		}
+	}
+	if p.Refs != nil {
+		if err := setPasswordRefs(tx, p.ID, p.Refs); err != nil {
+			return err
+		}
+	}
+	return prunePasswordHistory(tx, p.ID)
+}
+
+// updatePassword 写入条目的全部可编辑字段，收藏状态和使用统计由单独的接口修改
+func updatePassword(q querier, p models.Password) error {
 	// 设置更新时间为当前时间
 	p.UpdatedAt = time.Now()
 
-	// 将AuthLogins转换为JSON
-	authLoginsJSON, err := json.Marshal(p.AuthLogins)
+	_, err := q.Exec(
+		"UPDATE passwords SET item_type = ?, name = ?, username = ?, phone = ?, password = ?, notes = ?, item_data = ?, item_search = ?, protected = ?, expires_at = ?, rotation_days = ?, strength = ?, folder_id = ?, updated_at = ? WHERE id = ?",
+		p.ItemType, p.Name, p.Username, p.Phone, p.Password, p.Notes, p.ItemCipher, p.ItemSearch, p.Protected, p.ExpiresAt, p.RotationDays, p.Strength, p.FolderID, p.UpdatedAt, p.ID,
+	)
+	return err
+}
+
+// DeletePassword 将条目移入回收站，条目不存在或已在回收站中时返回sql.ErrNoRows。
+// revision不为0时只有条目当前修订号与之相同才删除，否则返回ErrRevisionConflict
+func DeletePassword(id, revision int) error {
+	return withTx(func(tx *sql.Tx) error {
+		return deletePasswordTx(tx, id, revision)
+	})
+}
+
+// deletePasswordTx 在已有事务中执行DeletePassword
+func deletePasswordTx(q querier, id, revision int) error {
+	if err := bumpRevision(q, id, revision); err != nil {
+		return err
+	}
+	_, err := q.Exec("UPDATE passwords SET deleted_at = ? WHERE id = ?", time.Now(), id)
+	return err
+}
+
+// SetPasswordFavorite 设置条目的收藏状态，不改变更新时间
+func SetPasswordFavorite(id int, favorite bool) error {
+	result, err := DB.Exec("UPDATE passwords SET favorite = ? WHERE id = ? AND deleted_at IS NULL", favorite, id)
 	if err != nil {
 		return err
 	}
+	return requireAffected(result)
+}
 
-	_, err = DB.Exec(
-		"UPDATE passwords SET name = ?, username = ?, phone = ?, password = ?, website = ?, auth_logins = ?, notes = ?, updated_at = ? WHERE id = ?",
-		p.Name, p.Username, p.Phone, p.Password, p.Website, string(authLoginsJSON), p.Notes, p.UpdatedAt, p.ID,
+// RecordPasswordUse 记录一次条目使用（查看、复制或获取验证码），不改变更新时间
+func RecordPasswordUse(id int) error {
+	result, err := DB.Exec(
+		"UPDATE passwords SET use_count = use_count + 1, last_used_at = ? WHERE id = ? AND deleted_at IS NULL",
+		time.Now(), id,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
 }
 
-// DeletePassword 删除密码
-func DeletePassword(id int) error {
-	_, err := DB.Exec("DELETE FROM passwords WHERE id = ?", id)
-	return err
+// requireAffected 语句未影响任何行时返回sql.ErrNoRows
+func requireAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
 
 // GetSetting 获取配置项
@@ -368,6 +676,28 @@ func GetSetting(key string) (string, error) {
 	return value, err
 }
 
+// GetIntSetting 获取整数配置项，未设置或格式错误时返回默认值
+func GetIntSetting(key string, defaultValue int) int {
+	if DB == nil {
+		return defaultValue
+	}
+	return getIntSetting(DB, key, defaultValue)
+}
+
+func getIntSetting(q querier, key string, defaultValue int) int {
+	var value string
+	if err := q.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value); err != nil {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("配置项 %s 的值无效: %q，使用默认值 %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
 // SetSetting 设置配置项
 func SetSetting(key, value string) error {
 	if DB == nil {
@@ -382,40 +712,6 @@ func SetSetting(key, value string) error {
 	return err
 }
 
-// SearchPasswordsByName 通过名称搜索密码
-func SearchPasswordsByName(query string) ([]models.Password, error) {
-	rows, err := DB.Query("SELECT id, name, username, phone, password, website, auth_logins, notes, created_at, updated_at FROM passwords WHERE name LIKE ?", "%"+query+"%")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var passwords []models.Password
-	for rows.Next() {
-		var p models.Password
-		var authLoginsJSON string
-		err := rows.Scan(&p.ID, &p.Name, &p.Username, &p.Phone, &p.Password, &p.Website, &authLoginsJSON, &p.Notes, &p.CreatedAt, &p.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-
-		// 解析JSON格式的AuthLogins
-		if authLoginsJSON != "" {
-			if err := json.Unmarshal([]byte(authLoginsJSON), &p.AuthLogins); err != nil {
-				log.Printf("Error unmarshaling auth_logins: %v", err)
-			}
-		}
-
-		passwords = append(passwords, p)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return passwords, nil
-}
-
 // GetDBFolder 获取数据库文件夹路径
 func GetDBFolder() string {
 	// 获取当前工作目录
@@ -434,6 +730,11 @@ func GetDBFolder() string {
 	return dataPath
 }
 
+// GetDBPath 获取数据库文件路径
+func GetDBPath() string {
+	return filepath.Join(GetDBFolder(), dbFile)
+}
+
 // 辅助函数：复制文件
 func copyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
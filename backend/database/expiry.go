@@ -0,0 +1,83 @@
+package database
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// 提前多少天提醒条目即将到期
+const (
+	ExpiryReminderSetting     = "expiry_reminder_days"
+	DefaultExpiryReminderDays = 14
+)
+
+// ExpiryCheckSetting 记录上次执行到期检查的时间（RFC 3339）
+const ExpiryCheckSetting = "expiry_check_last_run"
+
+// passwordDue 计算条目的到期时间，同时设置了过期时间和更换周期时取较早者。
+// 两者都未设置时返回false
+func passwordDue(expiresAt *time.Time, rotationDays int, changedAt time.Time) (time.Time, string, bool) {
+	var due time.Time
+	var reason string
+	if rotationDays > 0 {
+		due = changedAt.AddDate(0, 0, rotationDays)
+		reason = models.DueReasonRotation
+	}
+	if expiresAt != nil && (reason == "" || expiresAt.Before(due)) {
+		due = *expiresAt
+		reason = models.DueReasonExpires
+	}
+	return due.UTC().Truncate(time.Second), reason, reason != ""
+}
+
+// ExpiringPasswords 获取在days天内到期或已过期的条目，按到期时间排序，不包括回收站中的条目
+func ExpiringPasswords(days int) ([]models.ExpiringEntry, error) {
+	rows, err := DB.Query(`SELECT id, name, item_type, expires_at, rotation_days, password_changed_at, created_at
+		FROM passwords WHERE deleted_at IS NULL AND (expires_at IS NOT NULL OR rotation_days > 0)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	limit := now.AddDate(0, 0, days)
+	entries := []models.ExpiringEntry{}
+	for rows.Next() {
+		var e models.ExpiringEntry
+		var expiresAt, changedAt sql.NullTime
+		var rotationDays int
+		var createdAt time.Time
+		if err := rows.Scan(&e.ID, &e.Name, &e.ItemType, &expiresAt, &rotationDays, &changedAt, &createdAt); err != nil {
+			return nil, err
+		}
+
+		// 没有密码修改时间的条目从创建时开始计算更换周期
+		changed := createdAt
+		if changedAt.Valid {
+			changed = changedAt.Time
+		}
+		var expires *time.Time
+		if expiresAt.Valid {
+			expires = &expiresAt.Time
+		}
+
+		due, reason, ok := passwordDue(expires, rotationDays, changed)
+		if !ok || due.After(limit) {
+			continue
+		}
+		e.DueAt = due
+		e.Reason = reason
+		e.Overdue = !due.After(now)
+		e.DaysLeft = int(due.Sub(now).Hours() / 24)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DueAt.Before(entries[j].DueAt) })
+	return entries, nil
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+func TestPasswordDue(t *testing.T) {
+	changed := time.Date(2026, 1, 1, 8, 30, 15, 500, time.UTC)
+	early := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	local := time.Date(2026, 1, 10, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))
+
+	tests := []struct {
+		name         string
+		expiresAt    *time.Time
+		rotationDays int
+		wantDue      time.Time
+		wantReason   string
+		wantOK       bool
+	}{
+		{"neither", nil, 0, time.Time{}, "", false},
+		{"rotation only", nil, 90, time.Date(2026, 4, 1, 8, 30, 15, 0, time.UTC), models.DueReasonRotation, true},
+		{"expires only", &late, 0, late, models.DueReasonExpires, true},
+		{"expires before rotation", &early, 90, early, models.DueReasonExpires, true},
+		{"rotation before expires", &late, 30, time.Date(2026, 1, 31, 8, 30, 15, 0, time.UTC), models.DueReasonRotation, true},
+		{"expires in another zone", &local, 0, time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), models.DueReasonExpires, true},
+		{"negative rotation ignored", nil, -1, time.Time{}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, reason, ok := passwordDue(tt.expiresAt, tt.rotationDays, changed)
+			if ok != tt.wantOK || reason != tt.wantReason || !due.Equal(tt.wantDue) {
+				t.Errorf("passwordDue() = %v, %q, %v; want %v, %q, %v", due, reason, ok, tt.wantDue, tt.wantReason, tt.wantOK)
+			}
+			if ok && due.Location() != time.UTC {
+				t.Errorf("due location = %v, want UTC", due.Location())
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// setPasswordFields 保存条目的自定义字段：带ID的已有字段原位更新以保持ID不变，
+// 新字段插入，未提交的字段删除。字段顺序按提交顺序保存
+func setPasswordFields(q querier, passwordID int, fields []models.CustomField) error {
+	existing := make(map[int]bool)
+	rows, err := q.Query("SELECT id FROM password_fields WHERE password_id = ?", passwordID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	kept := make(map[int]bool)
+	for position, f := range fields {
+		if f.ID != 0 && existing[f.ID] && !kept[f.ID] {
+			_, err := q.Exec(
+				"UPDATE password_fields SET name = ?, type = ?, value = ?, position = ? WHERE id = ?",
+				f.Name, f.Type, f.Value, position, f.ID,
+			)
+			if err != nil {
+				return err
+			}
+			kept[f.ID] = true
+			continue
+		}
+
+		_, err := q.Exec(
+			"INSERT INTO password_fields (password_id, name, type, value, position) VALUES (?, ?, ?, ?, ?)",
+			passwordID, f.Name, f.Type, f.Value, position,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for id := range existing {
+		if kept[id] {
+			continue
+		}
+		if _, err := q.Exec("DELETE FROM password_fields WHERE id = ?", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachFields 为条目填充自定义字段，没有字段的条目得到空数组
+func attachFields(q querier, passwords []models.Password) error {
+	if len(passwords) == 0 {
+		return nil
+	}
+
+	query := "SELECT id, password_id, name, type, value FROM password_fields"
+	where, args := passwordIDFilter("password_id", passwords)
+	query += where
+	query += " ORDER BY password_id, position, id"
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	fieldsByID := make(map[int][]models.CustomField)
+	for rows.Next() {
+		var f models.CustomField
+		var passwordID int
+		if err := rows.Scan(&f.ID, &passwordID, &f.Name, &f.Type, &f.Value); err != nil {
+			return err
+		}
+		fieldsByID[passwordID] = append(fieldsByID[passwordID], f)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range passwords {
+		if fields, ok := fieldsByID[passwords[i].ID]; ok {
+			passwords[i].Fields = fields
+		} else {
+			passwords[i].Fields = []models.CustomField{}
+		}
+	}
+	return nil
+}
+
+// UpdateFieldValue 更新自定义字段的值，仅当当前值仍为oldValue时生效，
+// 用于HOTP计数器递增，避免并发请求生成相同的密码。字段所属条目的修订号同时加一
+func UpdateFieldValue(fieldID int, oldValue, newValue string) error {
+	return withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			"UPDATE password_fields SET value = ? WHERE id = ? AND value = ?",
+			newValue, fieldID, oldValue,
+		)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE passwords SET revision = revision + 1 WHERE id = (SELECT password_id FROM password_fields WHERE id = ?)", fieldID)
+		return err
+	})
+}
@@ -0,0 +1,285 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// 删除文件夹时对其内容的处理方式
+const (
+	// FolderDeleteCascade 子文件夹一并删除，其中的条目移入回收站
+	FolderDeleteCascade = "cascade"
+	// FolderDeleteReparent 子文件夹和条目移动到被删除文件夹的上级
+	FolderDeleteReparent = "reparent"
+)
+
+var (
+	// ErrFolderCycle 移动后文件夹会成为自身的下级
+	ErrFolderCycle = errors.New("folder cannot be moved into its own subtree")
+	// ErrParentFolderNotFound 上级文件夹不存在
+	ErrParentFolderNotFound = errors.New("parent folder not found")
+)
+
+const folderColumns = "f.id, f.name, f.parent_id, f.created_at, f.updated_at"
+
+// scanFolder 读取一条文件夹记录
+func scanFolder(row rowScanner, extra ...interface{}) (models.Folder, error) {
+	var f models.Folder
+	var parentID sql.NullInt64
+
+	dest := append([]interface{}{&f.ID, &f.Name, &parentID, &f.CreatedAt, &f.UpdatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return f, err
+	}
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		f.ParentID = &id
+	}
+	return f, nil
+}
+
+// GetAllFolders 获取所有文件夹及其直接包含的条目数量（不含回收站）
+func GetAllFolders() ([]models.Folder, error) {
+	rows, err := DB.Query(`
+		SELECT ` + folderColumns + `, COUNT(p.id)
+		FROM folders f
+		LEFT JOIN passwords p ON p.folder_id = f.id AND p.deleted_at IS NULL
+		GROUP BY f.id
+		ORDER BY f.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := []models.Folder{}
+	for rows.Next() {
+		var count int
+		f, err := scanFolder(rows, &count)
+		if err != nil {
+			return nil, err
+		}
+		f.Count = count
+		folders = append(folders, f)
+	}
+	return folders, rows.Err()
+}
+
+// GetFolderByID 通过ID获取文件夹
+func GetFolderByID(id int) (models.Folder, error) {
+	return scanFolder(DB.QueryRow("SELECT "+folderColumns+" FROM folders f WHERE f.id = ?", id))
+}
+
+// folderSubtree 获取文件夹及其所有下级文件夹的ID
+func folderSubtree(q querier, id int) ([]int, error) {
+	rows, err := q.Query(`
+		WITH RECURSIVE subtree(id) AS (
+			SELECT ?
+			UNION
+			SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id
+		)
+		SELECT id FROM subtree
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var folderID int
+		if err := rows.Scan(&folderID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, folderID)
+	}
+	return ids, rows.Err()
+}
+
+// checkParentFolder 检查上级文件夹是否存在
+func checkParentFolder(q querier, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+
+	var count int
+	if err := q.QueryRow("SELECT COUNT(*) FROM folders WHERE id = ?", *parentID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrParentFolderNotFound
+	}
+	return nil
+}
+
+// CreateFolder 创建文件夹
+func CreateFolder(name string, parentID *int) (int64, error) {
+	var id int64
+	err := withTx(func(tx *sql.Tx) error {
+		if err := checkParentFolder(tx, parentID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result, err := tx.Exec(
+			"INSERT INTO folders (name, parent_id, created_at, updated_at) VALUES (?, ?, ?, ?)",
+			name, parentID, now, now,
+		)
+		if err != nil {
+			return err
+		}
+		id, err = result.LastInsertId()
+		return err
+	})
+	return id, err
+}
+
+// UpdateFolder 重命名或移动文件夹，下级文件夹随之移动
+func UpdateFolder(id int, name string, parentID *int) error {
+	return withTx(func(tx *sql.Tx) error {
+		if err := checkParentFolder(tx, parentID); err != nil {
+			return err
+		}
+
+		if parentID != nil {
+			subtree, err := folderSubtree(tx, id)
+			if err != nil {
+				return err
+			}
+			for _, folderID := range subtree {
+				if folderID == *parentID {
+					return ErrFolderCycle
+				}
+			}
+		}
+
+		result, err := tx.Exec(
+			"UPDATE folders SET name = ?, parent_id = ?, updated_at = ? WHERE id = ?",
+			name, parentID, time.Now(), id,
+		)
+		if err != nil {
+			return err
+		}
+		return requireAffected(result)
+	})
+}
+
+// DeleteFolder 删除文件夹，mode决定子文件夹和条目的处理方式，返回移入回收站的条目数量
+func DeleteFolder(id int, mode string) (int64, error) {
+	var trashed int64
+	err := withTx(func(tx *sql.Tx) error {
+		var parentID sql.NullInt64
+		if err := tx.QueryRow("SELECT parent_id FROM folders WHERE id = ?", id).Scan(&parentID); err != nil {
+			return err
+		}
+
+		switch mode {
+		case FolderDeleteReparent:
+			if _, err := tx.Exec("UPDATE folders SET parent_id = ?, updated_at = ? WHERE parent_id = ?", parentID, time.Now(), id); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("UPDATE passwords SET folder_id = ?, revision = revision + 1 WHERE folder_id = ?", parentID, id); err != nil {
+				return err
+			}
+
+		case FolderDeleteCascade:
+			subtree, err := folderSubtree(tx, id)
+			if err != nil {
+				return err
+			}
+			args := make([]interface{}, 0, len(subtree)+1)
+			args = append(args, time.Now())
+			for _, folderID := range subtree {
+				args = append(args, folderID)
+			}
+			in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(subtree)), ", ") + ")"
+			result, err := tx.Exec("UPDATE passwords SET deleted_at = ?, revision = revision + 1 WHERE deleted_at IS NULL AND folder_id IN "+in, args...)
+			if err != nil {
+				return err
+			}
+			if trashed, err = result.RowsAffected(); err != nil {
+				return err
+			}
+
+		default:
+			return errors.New("unknown folder delete mode: " + mode)
+		}
+
+		// 下级文件夹通过外键级联删除，条目的folder_id被置空
+		_, err := tx.Exec("DELETE FROM folders WHERE id = ?", id)
+		return err
+	})
+	return trashed, err
+}
+
+// FolderPaths 获取所有文件夹ID对应的完整路径，如 工作/开发
+func FolderPaths() (map[int]string, error) {
+	folders, err := GetAllFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]models.Folder, len(folders))
+	for _, f := range folders {
+		byID[f.ID] = f
+	}
+
+	paths := make(map[int]string, len(folders))
+	for _, f := range folders {
+		parts := []string{f.Name}
+		for parent := f.ParentID; parent != nil; {
+			p, ok := byID[*parent]
+			if !ok {
+				break
+			}
+			parts = append([]string{p.Name}, parts...)
+			parent = p.ParentID
+		}
+		paths[f.ID] = strings.Join(parts, "/")
+	}
+	return paths, nil
+}
+
+// ensureFolderPath 按路径逐级查找文件夹，不存在的文件夹自动创建，返回最后一级的ID
+func ensureFolderPath(q querier, path string) (*int, error) {
+	var parentID *int
+	for _, name := range strings.Split(path, "/") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		var id int
+		var err error
+		if parentID == nil {
+			err = q.QueryRow("SELECT id FROM folders WHERE parent_id IS NULL AND name = ? ORDER BY id LIMIT 1", name).Scan(&id)
+		} else {
+			err = q.QueryRow("SELECT id FROM folders WHERE parent_id = ? AND name = ? ORDER BY id LIMIT 1", *parentID, name).Scan(&id)
+		}
+		if err == sql.ErrNoRows {
+			now := time.Now()
+			result, err := q.Exec(
+				"INSERT INTO folders (name, parent_id, created_at, updated_at) VALUES (?, ?, ?, ?)",
+				name, parentID, now, now,
+			)
+			if err != nil {
+				return nil, err
+			}
+			newID, err := result.LastInsertId()
+			if err != nil {
+				return nil, err
+			}
+			id = int(newID)
+		} else if err != nil {
+			return nil, err
+		}
+
+		folderID := id
+		parentID = &folderID
+	}
+	return parentID, nil
+}
@@ -0,0 +1,282 @@
+package database
+
+import (
+	"math"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// 模糊匹配的得分（0-1）：包含关键词最高，其次是拼音首字母、拼写错误和跳字匹配。
+// 每个关键词取各字段中最高的得分，所有关键词都达到minFuzzyScore的条目才算匹配
+const (
+	minFuzzyScore = 0.5
+
+	scoreWordStart   = 1.0  // 字段中有以关键词开头的词
+	scoreSubstring   = 0.9  // 字段包含关键词
+	scoreInitials    = 0.95 // 名称的拼音首字母以关键词开头
+	scoreInitialsSub = 0.85 // 名称的拼音首字母包含关键词
+	scoreTypo        = 0.9  // 与某个词（或词的开头）只差几个字符，每差一个字符减去typoPenalty
+	typoPenalty      = 0.15
+	scoreSubsequence = 0.4 // 关键词的字符按顺序出现在字段中，越紧凑得分越高，最多再加0.3
+)
+
+// 各字段的权重
+const (
+	weightName     = 1.0
+	weightHost     = 0.9
+	weightTag      = 0.85
+	weightUsername = 0.8
+)
+
+// usageWeight 使用次数对相关度的加成：相关度乘以 1 + usageWeight*ln(1+使用次数)
+const usageWeight = 0.1
+
+// usageBoost 按使用次数计算相关度的加成，常用的条目排在前面
+func usageBoost(useCount int) float64 {
+	return 1 + usageWeight*math.Log1p(float64(max(useCount, 0)))
+}
+
+// fuzzyCandidate 参与模糊匹配的条目内容
+type fuzzyCandidate struct {
+	id       int
+	name     string
+	username string
+	hosts    []string
+	tags     []string
+	useCount int
+	initials []initialsVariant
+}
+
+// fuzzyMatch 一个关键词的最佳匹配，start和end为匹配内容在text中的位置（按字符计），用于生成摘要
+type fuzzyMatch struct {
+	score      float64
+	text       string
+	start, end int
+}
+
+// fuzzyScore 计算条目与所有关键词的模糊匹配得分（各关键词得分的平均值），
+// 有关键词未达到minFuzzyScore时返回0。同时返回第一个关键词的匹配位置
+func fuzzyScore(c fuzzyCandidate, terms []string) (float64, fuzzyMatch) {
+	var total float64
+	var first fuzzyMatch
+	for i, term := range terms {
+		m := bestFuzzyMatch(c, []rune(strings.ToLower(term)))
+		if m.score < minFuzzyScore {
+			return 0, fuzzyMatch{}
+		}
+		if i == 0 {
+			first = m
+		}
+		total += m.score
+	}
+	return total / float64(len(terms)), first
+}
+
+// bestFuzzyMatch 在条目的名称、名称的拼音首字母、网址的主机名、标签和用户名中找关键词的最佳匹配
+func bestFuzzyMatch(c fuzzyCandidate, term []rune) fuzzyMatch {
+	var best fuzzyMatch
+	consider := func(text string, weight float64) {
+		if m := matchText(text, term); m.score*weight > best.score {
+			m.score *= weight
+			best = m
+		}
+	}
+
+	consider(c.name, weightName)
+	if m := matchInitials(c, term); m.score > best.score {
+		best = m
+	}
+	for _, host := range c.hosts {
+		consider(host, weightHost)
+	}
+	for _, tag := range c.tags {
+		consider(tag, weightTag)
+	}
+	consider(c.username, weightUsername)
+	return best
+}
+
+// matchText 计算关键词与一段文字的匹配得分
+func matchText(text string, term []rune) fuzzyMatch {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(term) == 0 || len(lower) != len(runes) {
+		return fuzzyMatch{}
+	}
+
+	if at := runeIndex(lower, term); at >= 0 {
+		score := scoreSubstring
+		if at == 0 || !isWordRune(lower[at-1]) {
+			score = scoreWordStart
+		}
+		return fuzzyMatch{score: score, text: text, start: at, end: at + len(term)}
+	}
+
+	best := fuzzyMatch{}
+	if typos := maxTypos(len(term)); typos > 0 {
+		for _, w := range splitWords(lower) {
+			word := lower[w[0]:w[1]]
+			// 与长度相近的词的开头比较，同时适用于完整的词和还没输入完的词；
+			// 从与关键词等长的开头向两侧比较，编辑距离相同时标记的长度与关键词最接近
+			for k := 0; k <= 2*typos; k++ {
+				n := len(term) + (k+1)/2
+				if k%2 == 0 {
+					n = len(term) - k/2
+				}
+				if n < 1 || n > len(word) {
+					continue
+				}
+				d := osaDistance(term, word[:n])
+				if d > typos {
+					continue
+				}
+				if score := scoreTypo - typoPenalty*float64(d); score > best.score {
+					best = fuzzyMatch{score: score, text: text, start: w[0], end: w[0] + n}
+				}
+			}
+		}
+	}
+
+	if len(term) >= 3 {
+		if start, end, ok := subsequence(lower, term); ok {
+			compactness := float64(len(term)) / float64(end-start)
+			if score := scoreSubsequence + 0.3*compactness; score > best.score {
+				best = fuzzyMatch{score: score, text: text, start: start, end: end}
+			}
+		}
+	}
+	return best
+}
+
+// matchInitials 用关键词匹配名称的拼音首字母，关键词只能由字母和数字组成
+func matchInitials(c fuzzyCandidate, term []rune) fuzzyMatch {
+	if len(term) < 2 {
+		return fuzzyMatch{}
+	}
+	for _, r := range term {
+		if r >= unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return fuzzyMatch{}
+		}
+	}
+
+	best := fuzzyMatch{}
+	for _, v := range c.initials {
+		at := strings.Index(v.text, string(term))
+		if at < 0 {
+			continue
+		}
+		score := scoreInitialsSub
+		if at == 0 {
+			score = scoreInitials
+		}
+		if score*weightName > best.score {
+			best = fuzzyMatch{score: score * weightName, text: c.name, start: v.pos[at], end: v.pos[at+len(term)-1] + 1}
+		}
+	}
+	return best
+}
+
+// maxTypos 关键词允许的拼写错误数量，太短的关键词不做容错
+func maxTypos(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// splitWords 返回文字中每个词（连续的字母和数字）的起止位置
+func splitWords(s []rune) [][2]int {
+	var words [][2]int
+	start := -1
+	for i, r := range s {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+		} else if start >= 0 {
+			words = append(words, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, [2]int{start, len(s)})
+	}
+	return words
+}
+
+// osaDistance 计算两个字符串的编辑距离，相邻字符交换（如 githbu 和 github）算作一次编辑
+func osaDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// subsequence 检查sub的字符是否按顺序出现在s中，返回最紧凑的一次出现的起止位置
+func subsequence(s, sub []rune) (int, int, bool) {
+	bestStart, bestEnd, found := 0, 0, false
+	for start := range s {
+		if s[start] != sub[0] {
+			continue
+		}
+		j, end := 1, start+1
+		for ; end < len(s) && j < len(sub); end++ {
+			if s[end] == sub[j] {
+				j++
+			}
+		}
+		if j < len(sub) {
+			break
+		}
+		if !found || end-start < bestEnd-bestStart {
+			bestStart, bestEnd, found = start, end, true
+		}
+	}
+	return bestStart, bestEnd, found
+}
+
+// uriHost 返回网址的主机名，无法解析时返回原网址
+func uriHost(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		if u, err = url.Parse("https://" + uri); err != nil || u.Host == "" {
+			return uri
+		}
+	}
+	return u.Hostname()
+}
+
+// fuzzySnippet 为模糊匹配的条目生成摘要，标记匹配的内容
+func fuzzySnippet(m fuzzyMatch) string {
+	runes := []rune(m.text)
+	if m.end <= m.start || m.end > len(runes) {
+		return ""
+	}
+	return string(runes[:m.start]) + SnippetOpen + string(runes[m.start:m.end]) + SnippetClose + string(runes[m.end:])
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestOSADistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"github", "github", 0},
+		{"githbu", "github", 1}, // 相邻字符交换
+		{"gthub", "github", 1},
+		{"gitxub", "github", 1},
+		{"githubb", "github", 1},
+		{"ca", "abc", 3}, // OSA不允许对交换过的字符再编辑
+		{"cnsole", "console", 1},
+		{"支付宝", "支付包", 1},
+	}
+
+	for _, tt := range tests {
+		if got := osaDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("osaDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPinyinInitials(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want string
+	}{
+		{'招', "z"},
+		{'商', "s"},
+		{'银', "y"},
+		{'行', "hx"}, // 多音字
+		{'阿', "a"},
+		{'做', "z"},
+		{'a', ""},
+		{'😀', ""},
+	}
+
+	for _, tt := range tests {
+		if got := pinyinInitials(tt.r); got != tt.want {
+			t.Errorf("pinyinInitials(%q) = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestNameInitials(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"招商银行", []string{"zsyh", "zsyx"}},
+		{"QQ邮箱", []string{"qqyx", "qyx"}},
+		{"网易163邮箱", []string{"wy163yx", "wy1yx"}},
+		{"GitHub", []string{"g", "github"}},
+		{"--", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, v := range nameInitials(tt.name) {
+			got = append(got, v.text)
+			if len(v.pos) != len([]rune(v.text)) {
+				t.Errorf("nameInitials(%q) variant %q has %d positions", tt.name, v.text, len(v.pos))
+			}
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("nameInitials(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchText(t *testing.T) {
+	tests := []struct {
+		text, term string
+		score      float64
+		start, end int
+	}{
+		{"GitHub", "git", scoreWordStart, 0, 3},
+		{"My GitHub", "hub", scoreSubstring, 6, 9},
+		{"GitHub", "githbu", scoreTypo - typoPenalty, 0, 6},
+		{"Aliyun Console", "cnsole", scoreTypo - typoPenalty, 7, 14},
+		{"GitHub", "gub", scoreSubsequence + 0.3*3/6, 0, 6}, // 跳字匹配
+		{"GitHub", "gb", 0, 0, 0},                           // 太短，不做跳字匹配
+		{"abc", "xyz", 0, 0, 0},
+		{"GitHub", "", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		m := matchText(tt.text, []rune(tt.term))
+		if m.score != tt.score || m.start != tt.start || m.end != tt.end {
+			t.Errorf("matchText(%q, %q) = %v [%d,%d), want %v [%d,%d)", tt.text, tt.term, m.score, m.start, m.end, tt.score, tt.start, tt.end)
+		}
+	}
+}
+
+func TestSearchPasswordsFuzzyOption(t *testing.T) {
+	openSearchTestDB(t)
+
+	query, err := ParseSearchQuery("gihtub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exact, err := SearchPasswords(PasswordFilter{}, query, SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exact) != 0 {
+		t.Errorf("exact search returned %d entries, want 0", len(exact))
+	}
+
+	fuzzy, err := SearchPasswords(PasswordFilter{}, query, SearchOptions{Fuzzy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fuzzy) == 0 || fuzzy[0].ID != 1 {
+		t.Errorf("fuzzy search = %+v, want GitHub first", fuzzy)
+	}
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// 每个条目保留的历史版本数量
+const (
+	HistoryRetentionSetting = "password_history_retention"
+	DefaultHistoryRetention = 10
+)
+
+// addPasswordHistory 记录条目密码的一个历史版本
+func addPasswordHistory(q querier, passwordID int, encryptedPassword, reason string) error {
+	_, err := q.Exec(
+		"INSERT INTO password_history (password_id, password, reason, created_at) VALUES (?, ?, ?, ?)",
+		passwordID, encryptedPassword, reason, time.Now(),
+	)
+	return err
+}
+
+// prunePasswordHistory 删除超出保留数量的旧版本
+func prunePasswordHistory(q querier, passwordID int) error {
+	retention := getIntSetting(q, HistoryRetentionSetting, DefaultHistoryRetention)
+	_, err := q.Exec(`
+		DELETE FROM password_history
+		WHERE password_id = ? AND id NOT IN (
+			SELECT id FROM password_history WHERE password_id = ? ORDER BY id DESC LIMIT ?
+		)
+	`, passwordID, passwordID, retention)
+	return err
+}
+
+// PruneAllPasswordHistory 按当前保留数量清理所有条目的历史版本，用于保留数量调小之后
+func PruneAllPasswordHistory() error {
+	retention := GetIntSetting(HistoryRetentionSetting, DefaultHistoryRetention)
+	_, err := DB.Exec(`
+		DELETE FROM password_history
+		WHERE (
+			SELECT COUNT(*) FROM password_history newer
+			WHERE newer.password_id = password_history.password_id AND newer.id > password_history.id
+		) >= ?
+	`, retention)
+	return err
+}
+
+// GetPasswordHistory 获取条目的历史版本，按时间倒序
+func GetPasswordHistory(passwordID int) ([]models.PasswordHistory, error) {
+	rows, err := DB.Query(
+		"SELECT id, password_id, password, reason, created_at FROM password_history WHERE password_id = ? ORDER BY id DESC",
+		passwordID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.PasswordHistory{}
+	for rows.Next() {
+		var h models.PasswordHistory
+		if err := rows.Scan(&h.ID, &h.PasswordID, &h.Password, &h.Reason, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+// RestorePasswordHistory 将条目密码恢复为指定的历史版本，当前密码会作为新的历史版本保留。
+// 数据库中只有密文，恢复后强度分数清空，由调用方或后台任务重新计算
+func RestorePasswordHistory(passwordID, historyID int) error {
+	return withTx(func(tx *sql.Tx) error {
+		var restored string
+		err := tx.QueryRow(
+			"SELECT password FROM password_history WHERE id = ? AND password_id = ?",
+			historyID, passwordID,
+		).Scan(&restored)
+		if err != nil {
+			return err
+		}
+
+		var current string
+		if err := tx.QueryRow("SELECT password FROM passwords WHERE id = ?", passwordID).Scan(&current); err != nil {
+			return err
+		}
+
+		if current != "" && current != restored {
+			if err := addPasswordHistory(tx, passwordID, current, models.HistoryReasonRestore); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if _, err := tx.Exec("UPDATE passwords SET password = ?, password_changed_at = ?, updated_at = ?, strength = NULL, revision = revision + 1 WHERE id = ?", restored, now, now, passwordID); err != nil {
+			return err
+		}
+		return prunePasswordHistory(tx, passwordID)
+	})
+}
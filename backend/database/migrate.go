@@ -0,0 +1,331 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 内嵌的迁移脚本，文件名格式为 <版本号>_<名称>.sql，按版本号顺序执行
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// goMigrations 需要Go代码才能完成的迁移步骤（如解析JSON数据），
+// 在同版本SQL脚本之后、同一事务内执行
+var goMigrations = map[int]func(tx *sql.Tx) error{
+	11: migrateAuthLogins,
+}
+
+// Migration 表示一个数据库结构迁移
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// 迁移记录表
+const schemaMigrationsTableSQL = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)
+`
+
+// SQLite不支持 ADD COLUMN IF NOT EXISTS，执行前检查列是否已存在
+var addColumnPattern = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(\w+)\s+ADD\s+(?:COLUMN\s+)?(\w+)`)
+
+// loadMigrations 读取并排序所有内嵌的迁移脚本
+func loadMigrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		base := strings.TrimSuffix(fileName, ".sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("迁移文件名格式错误: %s", fileName)
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("迁移文件版本号无效: %s", fileName)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("迁移版本号重复: %s 与 %s", other, fileName)
+		}
+		seen[version] = fileName
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    parts[1],
+			SQL:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// appliedMigrations 获取已执行的迁移版本，迁移记录表不存在时视为没有执行过任何迁移
+func appliedMigrations(db *sql.DB) (map[int]bool, error) {
+	applied := make(map[int]bool)
+
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&count)
+	if err != nil || count == 0 {
+		return applied, err
+	}
+
+	rows, err := db.Query("SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// pendingMigrations 计算尚未执行的迁移以及当前的数据库版本
+func pendingMigrations(db *sql.DB) ([]Migration, int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	current := 0
+	var pending []Migration
+	for _, m := range migrations {
+		if applied[m.Version] {
+			if m.Version > current {
+				current = m.Version
+			}
+			continue
+		}
+		pending = append(pending, m)
+	}
+	return pending, current, nil
+}
+
+// runMigrations 执行所有待执行的迁移，backup为true时会在迁移前备份数据库文件
+func runMigrations(dbPath string, backup bool) error {
+	if _, err := DB.Exec(schemaMigrationsTableSQL); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+
+	pending, current, err := pendingMigrations(DB)
+	if err != nil {
+		return fmt.Errorf("读取迁移状态失败: %w", err)
+	}
+	if len(pending) == 0 {
+		log.Printf("数据库结构已是最新版本 (版本 %d)", current)
+		return nil
+	}
+
+	if backup {
+		// WAL模式下先将日志写回主文件，保证备份完整
+		if _, err := DB.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+			log.Printf("迁移前执行WAL检查点失败: %v", err)
+		}
+		backupPath := fmt.Sprintf("%s.pre-migrate-v%d-%s", dbPath, current, time.Now().Format("20060102150405"))
+		log.Printf("迁移前备份数据库到: %s", backupPath)
+		if err := copyFile(dbPath, backupPath); err != nil {
+			return fmt.Errorf("迁移前备份数据库失败: %w", err)
+		}
+	}
+
+	// 外键开关是连接级别的设置且不能在事务中修改，使用独立连接执行迁移，
+	// 以便重建表时不会触发级联删除
+	ctx := context.Background()
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	for _, m := range pending {
+		log.Printf("执行数据库迁移 %04d_%s", m.Version, m.Name)
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return fmt.Errorf("迁移 %04d_%s 失败: %w", m.Version, m.Name, err)
+		}
+	}
+
+	log.Printf("数据库迁移完成，当前版本 %d", pending[len(pending)-1].Version)
+	return nil
+}
+
+// applyMigration 在单个事务中执行一个迁移
+func applyMigration(ctx context.Context, conn *sql.Conn, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(m.SQL) {
+		if match := addColumnPattern.FindStringSubmatch(stmt); match != nil {
+			exists, err := columnExists(tx, match[1], match[2])
+			if err != nil {
+				return err
+			}
+			if exists {
+				log.Printf("列 %s.%s 已存在，跳过", match[1], match[2])
+				continue
+			}
+		}
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("%w\n%s", err, stmt)
+		}
+	}
+
+	if up, ok := goMigrations[m.Version]; ok {
+		if err := up(tx); err != nil {
+			return err
+		}
+	}
+
+	// 外键检查在提交前进行，发现问题时整个迁移回滚
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	violation := rows.Next()
+	rows.Close()
+	if violation {
+		return fmt.Errorf("迁移后存在外键约束冲突")
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)", m.Version, m.Name, time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// columnExists 检查表中是否存在指定列
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if strings.EqualFold(name, column) {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// splitStatements 将迁移脚本拆分为单条语句，触发器的 BEGIN ... END 块视为一条语句
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	inTrigger := false
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		upper := strings.ToUpper(trimmed)
+		if strings.HasPrefix(upper, "CREATE TRIGGER") || strings.HasPrefix(upper, "CREATE TEMP TRIGGER") {
+			inTrigger = true
+		}
+		if !strings.HasSuffix(trimmed, ";") {
+			continue
+		}
+		if inTrigger && upper != "END;" {
+			continue
+		}
+
+		statements = append(statements, strings.TrimSpace(current.String()))
+		current.Reset()
+		inTrigger = false
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// MigrationPlan 使用给定密钥打开数据库（不执行迁移），返回当前版本和待执行的迁移
+func MigrationPlan(key string) (int, []Migration, error) {
+	dbPath := GetDBPath()
+	if _, err := os.Stat(dbPath); err != nil {
+		return 0, nil, fmt.Errorf("无法访问数据库文件: %w", err)
+	}
+
+	dsn := dbPath
+	if key != "" {
+		dsn = encryptedDSN(dbPath, key)
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer db.Close()
+
+	pending, current, err := pendingMigrations(db)
+	if err != nil {
+		return 0, nil, fmt.Errorf("读取迁移状态失败(密钥可能不正确): %w", err)
+	}
+	return current, pending, nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// openTestDB 在临时目录中创建数据库并执行所有迁移，测试结束后恢复原来的全局连接
+func openTestDB(t *testing.T) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() {
+		db.Close()
+		DB = previous
+	})
+
+	if err := runMigrations(dbPath, false); err != nil {
+		t.Fatalf("runMigrations: %v", err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "empty",
+			script: "\n-- 注释\n\n",
+			want:   nil,
+		},
+		{
+			name:   "single statement",
+			script: "CREATE TABLE a (id INTEGER);",
+			want:   []string{"CREATE TABLE a (id INTEGER);"},
+		},
+		{
+			name: "comments and multiple lines",
+			script: `-- 第一条
+CREATE TABLE a (
+	id INTEGER
+);
+
+-- 第二条
+CREATE INDEX idx_a ON a(id);
+`,
+			want: []string{"CREATE TABLE a (\n\tid INTEGER\n);", "CREATE INDEX idx_a ON a(id);"},
+		},
+		{
+			name: "trigger body kept together",
+			script: `CREATE TRIGGER t AFTER INSERT ON a BEGIN
+	UPDATE b SET n = n + 1;
+	DELETE FROM c;
+END;
+INSERT INTO a VALUES (1);`,
+			want: []string{
+				"CREATE TRIGGER t AFTER INSERT ON a BEGIN\n\tUPDATE b SET n = n + 1;\n\tDELETE FROM c;\nEND;",
+				"INSERT INTO a VALUES (1);",
+			},
+		},
+		{
+			name:   "missing final semicolon",
+			script: "UPDATE a SET id = 1;\nDELETE FROM a",
+			want:   []string{"UPDATE a SET id = 1;", "DELETE FROM a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations loaded")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d, want consecutive versions from 1", i, m.Version)
+		}
+		if len(splitStatements(m.SQL)) == 0 {
+			t.Errorf("migration %04d_%s has no statements", m.Version, m.Name)
+		}
+	}
+}
+
+func TestRunMigrationsIdempotent(t *testing.T) {
+	openTestDB(t)
+
+	pending, current, err := pendingMigrations(DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("%d migrations still pending after runMigrations", len(pending))
+	}
+
+	migrations, _ := loadMigrations()
+	if want := migrations[len(migrations)-1].Version; current != want {
+		t.Errorf("current version = %d, want %d", current, want)
+	}
+
+	if err := runMigrations("", false); err != nil {
+		t.Errorf("second runMigrations: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+-- 初始表结构，与引入迁移机制之前的数据库保持一致
+CREATE TABLE IF NOT EXISTS settings (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS passwords (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	username TEXT,
+	phone TEXT,
+	password TEXT NOT NULL,
+	website TEXT,
+	auth_logins TEXT,
+	notes TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
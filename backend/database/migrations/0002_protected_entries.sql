@@ -0,0 +1,2 @@
+-- 受保护条目：查看密码前需要重新验证主密码
+ALTER TABLE passwords ADD COLUMN protected INTEGER NOT NULL DEFAULT 0;
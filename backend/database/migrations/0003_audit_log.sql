@@ -0,0 +1,12 @@
+-- 敏感数据访问审计日志
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	action TEXT NOT NULL,
+	password_id INTEGER,
+	field TEXT,
+	client_ip TEXT,
+	user_agent TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_password_id ON audit_log(password_id);
@@ -0,0 +1,10 @@
+-- 条目密码的历史版本，保存修改前的加密值
+CREATE TABLE IF NOT EXISTS password_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	password_id INTEGER NOT NULL REFERENCES passwords(id) ON DELETE CASCADE,
+	password TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_password_id ON password_history(password_id);
@@ -0,0 +1,4 @@
+-- 回收站：删除条目时只记录删除时间，超过保留期限后彻底清除
+ALTER TABLE passwords ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_passwords_deleted_at ON passwords(deleted_at);
@@ -0,0 +1,29 @@
+-- 层级文件夹，删除文件夹时子文件夹一并删除，条目回到根目录
+CREATE TABLE IF NOT EXISTS folders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	parent_id INTEGER REFERENCES folders(id) ON DELETE CASCADE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_folders_parent_id ON folders(parent_id);
+
+ALTER TABLE passwords ADD COLUMN folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_passwords_folder_id ON passwords(folder_id);
+
+-- 标签，名称不区分大小写
+CREATE TABLE IF NOT EXISTS tags (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS password_tags (
+	password_id INTEGER NOT NULL REFERENCES passwords(id) ON DELETE CASCADE,
+	tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (password_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_tags_tag_id ON password_tags(tag_id);
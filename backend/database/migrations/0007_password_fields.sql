@@ -0,0 +1,11 @@
+-- 条目的自定义字段，hidden和totp类型的值单独加密保存
+CREATE TABLE IF NOT EXISTS password_fields (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	password_id INTEGER NOT NULL REFERENCES passwords(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL,
+	value TEXT NOT NULL DEFAULT '',
+	position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_fields_password_id ON password_fields(password_id);
@@ -0,0 +1,12 @@
+-- 条目附件的元数据，文件内容分块加密后保存在 data/attachments 目录
+CREATE TABLE IF NOT EXISTS attachments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	password_id INTEGER NOT NULL REFERENCES passwords(id) ON DELETE CASCADE,
+	file_name TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	blob_id TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_password_id ON attachments(password_id);
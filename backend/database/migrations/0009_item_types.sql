@@ -0,0 +1,33 @@
+-- 引入条目类型：重建passwords表，增加item_type和加密保存的item_data，以及用于搜索的非敏感类型字段item_search，
+-- 密码等登录字段改为可选（默认空字符串），安全笔记、银行卡等类型不再需要填写占位值
+CREATE TABLE passwords_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_type TEXT NOT NULL DEFAULT 'login',
+	name TEXT NOT NULL,
+	username TEXT NOT NULL DEFAULT '',
+	phone TEXT NOT NULL DEFAULT '',
+	password TEXT NOT NULL DEFAULT '',
+	website TEXT NOT NULL DEFAULT '',
+	auth_logins TEXT NOT NULL DEFAULT '',
+	notes TEXT NOT NULL DEFAULT '',
+	item_data TEXT NOT NULL DEFAULT '',
+	item_search TEXT NOT NULL DEFAULT '',
+	protected INTEGER NOT NULL DEFAULT 0,
+	folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	deleted_at TIMESTAMP
+);
+
+INSERT INTO passwords_new (id, item_type, name, username, phone, password, website, auth_logins, notes, protected, folder_id, created_at, updated_at, deleted_at)
+SELECT id, 'login', name, COALESCE(username, ''), COALESCE(phone, ''), COALESCE(password, ''), COALESCE(website, ''),
+	COALESCE(auth_logins, ''), COALESCE(notes, ''), protected, folder_id, created_at, updated_at, deleted_at
+FROM passwords;
+
+DROP TABLE passwords;
+
+ALTER TABLE passwords_new RENAME TO passwords;
+
+CREATE INDEX IF NOT EXISTS idx_passwords_deleted_at ON passwords(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_passwords_folder_id ON passwords(folder_id);
+CREATE INDEX IF NOT EXISTS idx_passwords_item_type ON passwords(item_type);
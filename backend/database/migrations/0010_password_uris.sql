@@ -0,0 +1,44 @@
+-- 条目支持多个网址，每个网址单独设置匹配规则。
+-- 原website列的值迁移为一条按基础域名匹配的网址，然后重建passwords表去掉website列
+CREATE TABLE IF NOT EXISTS password_uris (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	password_id INTEGER NOT NULL REFERENCES passwords(id) ON DELETE CASCADE,
+	uri TEXT NOT NULL,
+	match_mode TEXT NOT NULL DEFAULT 'domain',
+	position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_uris_password_id ON password_uris(password_id);
+
+INSERT INTO password_uris (password_id, uri, match_mode, position)
+SELECT id, TRIM(website), 'domain', 0 FROM passwords WHERE TRIM(COALESCE(website, '')) != '';
+
+CREATE TABLE passwords_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_type TEXT NOT NULL DEFAULT 'login',
+	name TEXT NOT NULL,
+	username TEXT NOT NULL DEFAULT '',
+	phone TEXT NOT NULL DEFAULT '',
+	password TEXT NOT NULL DEFAULT '',
+	auth_logins TEXT NOT NULL DEFAULT '',
+	notes TEXT NOT NULL DEFAULT '',
+	item_data TEXT NOT NULL DEFAULT '',
+	item_search TEXT NOT NULL DEFAULT '',
+	protected INTEGER NOT NULL DEFAULT 0,
+	folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	deleted_at TIMESTAMP
+);
+
+INSERT INTO passwords_new (id, item_type, name, username, phone, password, auth_logins, notes, item_data, item_search, protected, folder_id, created_at, updated_at, deleted_at)
+SELECT id, item_type, name, username, phone, password, auth_logins, notes, item_data, item_search, protected, folder_id, created_at, updated_at, deleted_at
+FROM passwords;
+
+DROP TABLE passwords;
+
+ALTER TABLE passwords_new RENAME TO passwords;
+
+CREATE INDEX IF NOT EXISTS idx_passwords_deleted_at ON passwords(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_passwords_folder_id ON passwords(folder_id);
+CREATE INDEX IF NOT EXISTS idx_passwords_item_type ON passwords(item_type);
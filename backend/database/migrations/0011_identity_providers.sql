@@ -0,0 +1,34 @@
+-- 授权登录由固定的13个布尔值改为可扩展的身份提供方（SSO）登记表。
+-- password_providers记录条目使用的登录方式，account_id指向保存该SSO账号的条目。
+-- legacy_key对应原auth_logins中的JSON键，用于迁移旧数据和导入旧版本导出文件
+CREATE TABLE IF NOT EXISTS identity_providers (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	legacy_key TEXT UNIQUE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS password_providers (
+	password_id INTEGER NOT NULL REFERENCES passwords(id) ON DELETE CASCADE,
+	provider_id INTEGER NOT NULL REFERENCES identity_providers(id) ON DELETE CASCADE,
+	account_id INTEGER REFERENCES passwords(id) ON DELETE SET NULL,
+	PRIMARY KEY (password_id, provider_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_providers_provider_id ON password_providers(provider_id);
+CREATE INDEX IF NOT EXISTS idx_password_providers_account_id ON password_providers(account_id);
+
+INSERT OR IGNORE INTO identity_providers (name, legacy_key) VALUES
+	('Google', 'google'),
+	('微信', 'wechat'),
+	('微博', 'weibo'),
+	('百度', 'baidu'),
+	('Facebook', 'facebook'),
+	('GitHub', 'github'),
+	('QQ', 'qq'),
+	('支付宝', 'alipay'),
+	('淘宝', 'taobao'),
+	('钉钉', 'dingtalk'),
+	('抖音', 'douyin'),
+	('飞书', 'feishu'),
+	('X(Twitter)', 'twitter');
@@ -0,0 +1,29 @@
+-- auth_logins已在0011迁移到password_providers，重建passwords表去掉该列
+CREATE TABLE passwords_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_type TEXT NOT NULL DEFAULT 'login',
+	name TEXT NOT NULL,
+	username TEXT NOT NULL DEFAULT '',
+	phone TEXT NOT NULL DEFAULT '',
+	password TEXT NOT NULL DEFAULT '',
+	notes TEXT NOT NULL DEFAULT '',
+	item_data TEXT NOT NULL DEFAULT '',
+	item_search TEXT NOT NULL DEFAULT '',
+	protected INTEGER NOT NULL DEFAULT 0,
+	folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	deleted_at TIMESTAMP
+);
+
+INSERT INTO passwords_new (id, item_type, name, username, phone, password, notes, item_data, item_search, protected, folder_id, created_at, updated_at, deleted_at)
+SELECT id, item_type, name, username, phone, password, notes, item_data, item_search, protected, folder_id, created_at, updated_at, deleted_at
+FROM passwords;
+
+DROP TABLE passwords;
+
+ALTER TABLE passwords_new RENAME TO passwords;
+
+CREATE INDEX IF NOT EXISTS idx_passwords_deleted_at ON passwords(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_passwords_folder_id ON passwords(folder_id);
+CREATE INDEX IF NOT EXISTS idx_passwords_item_type ON passwords(item_type);
@@ -0,0 +1,6 @@
+-- 收藏和使用统计：查看、复制或获取验证码时更新最近使用时间和使用次数
+ALTER TABLE passwords ADD COLUMN favorite INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE passwords ADD COLUMN last_used_at TIMESTAMP;
+ALTER TABLE passwords ADD COLUMN use_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_passwords_last_used_at ON passwords(last_used_at);
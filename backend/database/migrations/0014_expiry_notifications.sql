@@ -0,0 +1,26 @@
+-- 条目过期时间和定期更换周期（天，0表示不需要定期更换）。
+-- password_changed_at 为最近一次修改密码的时间，用于计算下次更换日期
+ALTER TABLE passwords ADD COLUMN expires_at TIMESTAMP;
+ALTER TABLE passwords ADD COLUMN rotation_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE passwords ADD COLUMN password_changed_at TIMESTAMP;
+
+-- 已有条目以最近一次进入历史记录的时间作为修改时间，没有历史记录时使用创建时间
+UPDATE passwords SET password_changed_at = COALESCE(
+	(SELECT MAX(created_at) FROM password_history WHERE password_history.password_id = passwords.id),
+	created_at
+) WHERE password_changed_at IS NULL;
+
+-- 后台任务生成的提醒，同一条目同一到期时间的同类提醒只生成一次
+CREATE TABLE IF NOT EXISTS notifications (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind TEXT NOT NULL,
+	password_id INTEGER REFERENCES passwords(id) ON DELETE CASCADE,
+	title TEXT NOT NULL,
+	message TEXT NOT NULL,
+	due_at TIMESTAMP,
+	read_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_dedup ON notifications(password_id, kind, due_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_read_at ON notifications(read_at);
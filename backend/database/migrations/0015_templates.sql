@@ -0,0 +1,25 @@
+-- 用户自定义的条目模板，如服务器、数据库账号等固定的字段组合。
+-- target为字段写入条目的位置：field为自定义字段，username/password/uri为条目的对应字段；
+-- generator为生成规则的JSON，未填写值且没有默认值时按规则生成
+CREATE TABLE IF NOT EXISTS templates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	description TEXT NOT NULL DEFAULT '',
+	item_type TEXT NOT NULL DEFAULT 'login',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS template_fields (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	template_id INTEGER NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL DEFAULT 'text',
+	target TEXT NOT NULL DEFAULT 'field',
+	required INTEGER NOT NULL DEFAULT 0,
+	default_value TEXT NOT NULL DEFAULT '',
+	generator TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_template_fields_template_id ON template_fields(template_id, position);
@@ -0,0 +1,11 @@
+-- 条目字段中的 {REF:P@I:<条目ID>} 引用，值在查看时解析，这里只记录引用关系，
+-- 用于列出引用某个条目的其他条目。field为引用所在的字段，target_field为被引用的字段
+CREATE TABLE IF NOT EXISTS password_refs (
+	password_id INTEGER NOT NULL REFERENCES passwords(id) ON DELETE CASCADE,
+	field TEXT NOT NULL,
+	target_id INTEGER NOT NULL REFERENCES passwords(id) ON DELETE CASCADE,
+	target_field TEXT NOT NULL,
+	PRIMARY KEY (password_id, field, target_id, target_field)
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_refs_target_id ON password_refs(target_id);
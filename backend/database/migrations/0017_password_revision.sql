@@ -0,0 +1,2 @@
+-- 条目的修订号，每次修改条目内容时加一，用于ETag和If-Match乐观并发控制
+ALTER TABLE passwords ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
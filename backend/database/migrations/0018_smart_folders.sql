@@ -0,0 +1,12 @@
+-- 条目密码的强度分数（0-4），保存条目时由服务端计算，用于按弱密码筛选。
+-- 没有密码或密码为字段引用时为NULL，升级前已有的条目由后台任务补算
+ALTER TABLE passwords ADD COLUMN strength INTEGER;
+
+-- 智能文件夹：保存的结构化搜索，打开时按query实时查询条目
+CREATE TABLE IF NOT EXISTS smart_folders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	query TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
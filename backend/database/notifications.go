@@ -0,0 +1,107 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// AddNotifications 保存提醒，已存在的同一条目同一到期时间的同类提醒会被忽略。
+// 返回实际新增的提醒，供发送到邮件或Webhook
+func AddNotifications(notes []models.Notification) ([]models.Notification, error) {
+	var added []models.Notification
+	err := withTx(func(tx *sql.Tx) error {
+		for _, n := range notes {
+			if n.CreatedAt.IsZero() {
+				n.CreatedAt = time.Now()
+			}
+			result, err := tx.Exec(
+				"INSERT OR IGNORE INTO notifications (kind, password_id, title, message, due_at, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+				n.Kind, n.PasswordID, n.Title, n.Message, n.DueAt, n.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+			if affected, err := result.RowsAffected(); err != nil {
+				return err
+			} else if affected == 0 {
+				continue
+			}
+			id, err := result.LastInsertId()
+			if err != nil {
+				return err
+			}
+			n.ID = int(id)
+			added = append(added, n)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// GetNotifications 获取提醒，最新的在前，unreadOnly为true时只返回未读提醒
+func GetNotifications(unreadOnly bool, limit int) ([]models.Notification, error) {
+	query := "SELECT id, kind, password_id, title, message, due_at, read_at, created_at FROM notifications"
+	if unreadOnly {
+		query += " WHERE read_at IS NULL"
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+
+	rows, err := DB.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		var passwordID sql.NullInt64
+		var dueAt, readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.Kind, &passwordID, &n.Title, &n.Message, &dueAt, &readAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		if passwordID.Valid {
+			id := int(passwordID.Int64)
+			n.PasswordID = &id
+		}
+		if dueAt.Valid {
+			n.DueAt = &dueAt.Time
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notes = append(notes, n)
+	}
+
+	return notes, rows.Err()
+}
+
+// CountUnreadNotifications 获取未读提醒的数量
+func CountUnreadNotifications() (int, error) {
+	var count int
+	err := DB.QueryRow("SELECT COUNT(*) FROM notifications WHERE read_at IS NULL").Scan(&count)
+	return count, err
+}
+
+// MarkNotificationRead 将提醒标记为已读，提醒不存在时返回sql.ErrNoRows
+func MarkNotificationRead(id int) error {
+	result, err := DB.Exec("UPDATE notifications SET read_at = COALESCE(read_at, ?) WHERE id = ?", time.Now(), id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// MarkAllNotificationsRead 将所有未读提醒标记为已读，返回标记的数量
+func MarkAllNotificationsRead() (int64, error) {
+	result, err := DB.Exec("UPDATE notifications SET read_at = ? WHERE read_at IS NULL", time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
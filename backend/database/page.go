@@ -0,0 +1,213 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// ErrInvalidCursor 分页游标无法解析，或与当前的排序方式不一致
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// sortKey 排序使用的一列，expr的值不能为NULL，以便按游标比较
+type sortKey struct {
+	expr string
+	desc bool
+}
+
+// 从未使用的条目 last_used_at 为NULL，按0比较，排在使用过的条目之后
+const lastUsedKey = "COALESCE(julianday(last_used_at), 0)"
+
+// sortKeys 各排序方式依次比较的列，最后都按id区分，保证顺序唯一，游标分页不会重复或遗漏
+var sortKeys = map[string][]sortKey{
+	"":           {{"id", false}},
+	SortRecent:   {{lastUsedKey, true}, {"name COLLATE NOCASE", false}, {"id", false}},
+	SortFrequent: {{"use_count", true}, {lastUsedKey, true}, {"name COLLATE NOCASE", false}, {"id", false}},
+	SortName:     {{"name COLLATE NOCASE", false}, {"id", false}},
+	SortUpdated:  {{"julianday(updated_at)", true}, {"id", true}},
+	SortCreated:  {{"julianday(created_at)", true}, {"id", true}},
+}
+
+// ValidSort 检查排序方式是否受支持
+func ValidSort(sort string) bool {
+	_, ok := sortKeys[strings.TrimPrefix(sort, "-")]
+	return ok
+}
+
+// sortKeysFor 返回排序方式对应的列，前面有 - 时每一列都反向
+func sortKeysFor(sort string) []sortKey {
+	keys := sortKeys[strings.TrimPrefix(sort, "-")]
+	if !strings.HasPrefix(sort, "-") {
+		return keys
+	}
+	reversed := make([]sortKey, len(keys))
+	for i, k := range keys {
+		reversed[i] = sortKey{expr: k.expr, desc: !k.desc}
+	}
+	return reversed
+}
+
+// orderClause 生成排序方式的ORDER BY子句
+func orderClause(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k.expr
+		if k.desc {
+			parts[i] += " DESC"
+		}
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// afterCursor 生成只返回排在游标之后的条目的条件，即
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...，降序的列使用 <
+func afterCursor(keys []sortKey, values []interface{}) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	for i, k := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].expr+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if k.desc {
+			op = " < ?"
+		}
+		parts = append(parts, k.expr+op)
+		args = append(args, values[i])
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return " AND (" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// pageCursor 游标的内容：排序方式和上一页最后一个条目的各排序列的值
+type pageCursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// encodeCursor 将游标编码为URL安全的文本
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 解析encodeCursor生成的游标，格式或取值无效时返回ErrInvalidCursor
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	// 排序列的值只会是文本或数字，其他类型（对象、数组、null等）说明游标被篡改
+	for _, v := range c.Values {
+		switch v.(type) {
+		case string, float64:
+		default:
+			return c, ErrInvalidCursor
+		}
+	}
+	return c, nil
+}
+
+// PasswordPage 一页条目
+type PasswordPage struct {
+	Passwords []models.Password
+	// Total 满足筛选条件的条目总数，不受分页影响
+	Total int
+	// NextCursor 获取下一页的游标，已是最后一页时为空
+	NextCursor string
+}
+
+// ListPasswordsPage 按筛选条件和排序方式分页获取条目，不包含回收站中的条目。
+// limit不大于0时返回全部条目；cursor为上一页返回的NextCursor，为空时从第一页开始
+func ListPasswordsPage(filter PasswordFilter, limit int, cursor string) (PasswordPage, error) {
+	var page PasswordPage
+	conditions, args, err := filterConditions(filter)
+	if err != nil {
+		return page, err
+	}
+	keys := sortKeysFor(filter.Sort)
+
+	if limit > 0 {
+		if err := DB.QueryRow("SELECT COUNT(*) FROM passwords WHERE deleted_at IS NULL"+conditions, args...).Scan(&page.Total); err != nil {
+			return page, err
+		}
+	}
+
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return page, err
+		}
+		if c.Sort != filter.Sort || len(c.Values) != len(keys) {
+			return page, ErrInvalidCursor
+		}
+		after, afterArgs := afterCursor(keys, c.Values)
+		conditions += after
+		args = append(args, afterArgs...)
+	}
+
+	exprs := make([]string, len(keys))
+	for i, k := range keys {
+		exprs[i] = k.expr
+	}
+	query := "SELECT " + passwordColumns + ", " + strings.Join(exprs, ", ") + " FROM passwords WHERE deleted_at IS NULL" + conditions + orderClause(keys)
+	if limit > 0 {
+		// 多取一条，用于判断是否还有下一页
+		query += " LIMIT ?"
+		args = append(args, limit+1)
+	}
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	passwords := []models.Password{}
+	var lastValues []interface{}
+	for rows.Next() {
+		if limit > 0 && len(passwords) == limit {
+			page.NextCursor = encodeCursor(pageCursor{Sort: filter.Sort, Values: lastValues})
+			break
+		}
+
+		values := make([]interface{}, len(keys))
+		dest := make([]interface{}, len(keys))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		p, err := scanPassword(extraScanner{row: rows, extra: dest})
+		if err != nil {
+			return page, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		passwords = append(passwords, p)
+		lastValues = values
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+	rows.Close()
+
+	if err := attachRelations(passwords); err != nil {
+		return page, err
+	}
+	page.Passwords = passwords
+	if limit <= 0 {
+		page.Total = len(passwords)
+	}
+	return page, nil
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/007Secret/007Password/models"
+)
+
+func TestAfterCursor(t *testing.T) {
+	tests := []struct {
+		keys     []sortKey
+		values   []interface{}
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			keys:     []sortKey{{"id", false}},
+			values:   []interface{}{float64(7)},
+			wantSQL:  " AND ((id > ?))",
+			wantArgs: []interface{}{float64(7)},
+		},
+		{
+			keys:     []sortKey{{"name COLLATE NOCASE", false}, {"id", false}},
+			values:   []interface{}{"GitHub", float64(3)},
+			wantSQL:  " AND ((name COLLATE NOCASE > ?) OR (name COLLATE NOCASE = ? AND id > ?))",
+			wantArgs: []interface{}{"GitHub", "GitHub", float64(3)},
+		},
+		{
+			keys:     []sortKey{{"use_count", true}, {lastUsedKey, true}, {"id", false}},
+			values:   []interface{}{float64(5), float64(2460000.5), float64(9)},
+			wantSQL:  " AND ((use_count < ?) OR (use_count = ? AND " + lastUsedKey + " < ?) OR (use_count = ? AND " + lastUsedKey + " = ? AND id > ?))",
+			wantArgs: []interface{}{float64(5), float64(5), float64(2460000.5), float64(5), float64(2460000.5), float64(9)},
+		},
+	}
+
+	for _, tt := range tests {
+		sql, args := afterCursor(tt.keys, tt.values)
+		if sql != tt.wantSQL || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("afterCursor(%v) = %q %v, want %q %v", tt.keys, sql, args, tt.wantSQL, tt.wantArgs)
+		}
+	}
+}
+
+func TestSortKeysForReversed(t *testing.T) {
+	keys := sortKeysFor("-" + SortName)
+	if len(keys) != 2 || !keys[0].desc || !keys[1].desc {
+		t.Errorf("sortKeysFor(-name) = %v, want every key descending", keys)
+	}
+	if sortKeys[SortName][0].desc {
+		t.Error("sortKeysFor modified the shared sort keys")
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []pageCursor{
+		{Sort: "", Values: []interface{}{float64(1)}},
+		{Sort: SortName, Values: []interface{}{"招商银行 / \"quoted\"", float64(42)}},
+		{Sort: "-" + SortFrequent, Values: []interface{}{float64(3), float64(2460123.25), "a", float64(8)}},
+	}
+
+	for _, c := range cursors {
+		got, err := decodeCursor(encodeCursor(c))
+		if err != nil {
+			t.Errorf("decodeCursor(encodeCursor(%+v)): %v", c, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("round trip = %+v, want %+v", got, c)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []string{
+		"",
+		"not base64!",
+		encode("not json"),
+		encode(`{"s":"name","v":[{"a":1},1]}`),
+		encode(`{"s":"name","v":[["x"],1]}`),
+		encode(`{"s":"name","v":[null,1]}`),
+		encode(`{"s":"name","v":[true,1]}`),
+		encode(`{"s":"name","v":"x"}`),
+	}
+
+	for _, s := range tests {
+		if c, err := decodeCursor(s); err != ErrInvalidCursor {
+			t.Errorf("decodeCursor(%q) = %+v, %v; want ErrInvalidCursor", s, c, err)
+		}
+	}
+}
+
+// 按每种排序方式逐页读取，每个条目应恰好出现一次
+func TestListPasswordsPageWalk(t *testing.T) {
+	openTestDB(t)
+	for i := 1; i <= 7; i++ {
+		// 名称有重复，确保按id区分先后
+		_, err := DB.Exec("INSERT INTO passwords (name, use_count, last_used_at) VALUES (?, ?, CASE WHEN ? THEN CURRENT_TIMESTAMP END)",
+			fmt.Sprintf("entry %d", i%3), i%2, i%2 == 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, sort := range []string{"", SortName, "-" + SortName, SortRecent, SortFrequent, SortUpdated, "-" + SortCreated} {
+		seen := map[int]bool{}
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 7 {
+				t.Fatalf("sort %q: too many pages", sort)
+			}
+			page, err := ListPasswordsPage(PasswordFilter{Sort: sort}, 3, cursor)
+			if err != nil {
+				t.Fatalf("sort %q: %v", sort, err)
+			}
+			if page.Total != 7 {
+				t.Errorf("sort %q: total = %d, want 7", sort, page.Total)
+			}
+			for _, p := range page.Passwords {
+				if seen[p.ID] {
+					t.Errorf("sort %q: entry %d returned twice", sort, p.ID)
+				}
+				seen[p.ID] = true
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if len(seen) != 7 {
+			t.Errorf("sort %q: saw %d entries, want 7", sort, len(seen))
+		}
+	}
+
+	// 游标不能用于其他排序方式
+	page, err := ListPasswordsPage(PasswordFilter{Sort: SortName}, 3, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListPasswordsPage(PasswordFilter{Sort: SortRecent}, 3, page.NextCursor); err != ErrInvalidCursor {
+		t.Errorf("cursor reused with another sort: err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestPasswordIDFilter(t *testing.T) {
+	where, args := passwordIDFilter("pt.password_id", []models.Password{{ID: 3}, {ID: 8}})
+	if where != " WHERE pt.password_id IN (?, ?)" || !reflect.DeepEqual(args, []interface{}{3, 8}) {
+		t.Errorf("passwordIDFilter = %q %v", where, args)
+	}
+
+	// 条目过多时不限制，避免超出SQLite的参数数量上限
+	if where, args := passwordIDFilter("password_id", make([]models.Password, maxAttachIDs+1)); where != "" || args != nil {
+		t.Errorf("passwordIDFilter(%d entries) = %q with %d args, want no condition", maxAttachIDs+1, where, len(args))
+	}
+}
+
+// 分页时只读取当前页条目的关联数据，其他条目的标签和网址不会混入
+func TestListPasswordsPageRelations(t *testing.T) {
+	openSearchTestDB(t)
+
+	page, err := ListPasswordsPage(PasswordFilter{Sort: SortName}, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Passwords) != 2 || page.Passwords[0].ID != 1 || page.Passwords[1].ID != 2 {
+		t.Fatalf("first page = %+v, want GitHub and GitLab", page.Passwords)
+	}
+	if got := page.Passwords[0]; !reflect.DeepEqual(got.Tags, []string{"work"}) || len(got.URIs) != 1 {
+		t.Errorf("GitHub tags %v uris %v", got.Tags, got.URIs)
+	}
+	if got := page.Passwords[1]; !reflect.DeepEqual(got.Tags, []string{"work"}) || len(got.URIs) != 0 || got.Fields == nil {
+		t.Errorf("GitLab tags %v uris %v fields %v", got.Tags, got.URIs, got.Fields)
+	}
+
+	next, err := ListPasswordsPage(PasswordFilter{Sort: SortName}, 2, page.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.Passwords) != 1 || len(next.Passwords[0].Tags) != 0 || next.Passwords[0].Providers == nil {
+		t.Errorf("second page = %+v, want 银行卡 without tags", next.Passwords)
+	}
+}
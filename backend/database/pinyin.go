@@ -0,0 +1,132 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// GB2312一级汉字（常用的3755个）按拼音排序，每个声母的第一个汉字的编码如下，
+// 据此可以得到汉字拼音的首字母。二级汉字按部首排序，无法得到首字母
+var pinyinInitialBounds = []struct {
+	code    int
+	initial byte
+}{
+	{0xB0A1, 'a'}, {0xB0C5, 'b'}, {0xB2C1, 'c'}, {0xB4EE, 'd'}, {0xB6EA, 'e'},
+	{0xB7A2, 'f'}, {0xB8C1, 'g'}, {0xB9FE, 'h'}, {0xBBF7, 'j'}, {0xBFA6, 'k'},
+	{0xC0AC, 'l'}, {0xC2E8, 'm'}, {0xC4C3, 'n'}, {0xC5B6, 'o'}, {0xC5BE, 'p'},
+	{0xC6DA, 'q'}, {0xC8BB, 'r'}, {0xC8F6, 's'}, {0xCBFA, 't'}, {0xCDDA, 'w'},
+	{0xCEF4, 'x'}, {0xD1B9, 'y'}, {0xD4D1, 'z'},
+}
+
+// GB2312一级汉字的最后一个编码
+const pinyinLastCode = 0xD7F9
+
+// 条目名称中常见的多音字，GB2312只按其中一个读音排序
+var pinyinPolyphones = map[rune]string{
+	'行': "hx", // 银行
+	'长': "cz",
+	'重': "zc",
+	'乐': "ly",
+	'单': "ds",
+	'藏': "cz",
+	'会': "hk",
+	'调': "dt",
+	'传': "cz",
+	'厦': "xs",
+	'朝': "cz",
+	'曾': "zc",
+	'种': "zc",
+	'省': "sx",
+	'区': "qo",
+}
+
+// 多音字和英文单词组合出的首字母串数量上限，超过后其余的只取第一种
+const maxPinyinVariants = 16
+
+// pinyinInitials 返回汉字拼音的所有可能首字母，无法识别的汉字返回空字符串
+func pinyinInitials(r rune) string {
+	if initials, ok := pinyinPolyphones[r]; ok {
+		return initials
+	}
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(string(r))
+	if err != nil || len(encoded) != 2 {
+		return ""
+	}
+	code := int(encoded[0])<<8 | int(encoded[1])
+	if code < pinyinInitialBounds[0].code || code > pinyinLastCode {
+		return ""
+	}
+	initial := pinyinInitialBounds[0].initial
+	for _, b := range pinyinInitialBounds {
+		if code < b.code {
+			break
+		}
+		initial = b.initial
+	}
+	return string(initial)
+}
+
+// initialsVariant 名称的一种首字母串，pos[i]为第i个首字母对应的字符在名称中的位置
+type initialsVariant struct {
+	text string
+	pos  []int
+}
+
+// nameInitials 生成名称的拼音首字母串，如「招商银行」为 zsyh。名称中的英文单词和数字可以只取第一个字符
+// 也可以取整个词，如「QQ邮箱」为 qyx 或 qqyx。名称中有多音字时返回每种读音组合的首字母串
+func nameInitials(name string) []initialsVariant {
+	variants := []initialsVariant{{}}
+	runes := []rune(strings.ToLower(name))
+	for i := 0; i < len(runes); i++ {
+		// 当前位置可选的首字母，每个选项的pos为各字母对应的字符位置
+		var options []initialsVariant
+		switch r := runes[i]; {
+		case isASCIIWordRune(r):
+			end := i + 1
+			for end < len(runes) && isASCIIWordRune(runes[end]) {
+				end++
+			}
+			options = append(options, initialsVariant{text: string(r), pos: []int{i}})
+			if end-i > 1 {
+				whole := initialsVariant{text: string(runes[i:end])}
+				for j := i; j < end; j++ {
+					whole.pos = append(whole.pos, j)
+				}
+				options = append(options, whole)
+			}
+			i = end - 1
+		case unicode.Is(unicode.Han, r):
+			for _, c := range pinyinInitials(r) {
+				options = append(options, initialsVariant{text: string(c), pos: []int{i}})
+			}
+		}
+		if len(options) == 0 {
+			continue
+		}
+		if len(variants)*len(options) > maxPinyinVariants {
+			options = options[:1]
+		}
+
+		next := make([]initialsVariant, 0, len(variants)*len(options))
+		for _, v := range variants {
+			for _, o := range options {
+				next = append(next, initialsVariant{
+					text: v.text + o.text,
+					pos:  append(append([]int(nil), v.pos...), o.pos...),
+				})
+			}
+		}
+		variants = next
+	}
+	if variants[0].text == "" {
+		return nil
+	}
+	return variants
+}
+
+// isASCIIWordRune 是否为英文字母或数字
+func isASCIIWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,290 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// ErrProviderExists 身份提供方名称已被使用
+var ErrProviderExists = errors.New("identity provider already exists")
+
+// GetIdentityProviders 获取所有身份提供方及使用该登录方式的条目数量（不含回收站）
+func GetIdentityProviders() ([]models.IdentityProvider, error) {
+	rows, err := DB.Query(`
+		SELECT ip.id, ip.name, ip.created_at, COUNT(p.id)
+		FROM identity_providers ip
+		LEFT JOIN password_providers pp ON pp.provider_id = ip.id
+		LEFT JOIN passwords p ON p.id = pp.password_id AND p.deleted_at IS NULL
+		GROUP BY ip.id
+		ORDER BY ip.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	providers := []models.IdentityProvider{}
+	for rows.Next() {
+		var ip models.IdentityProvider
+		if err := rows.Scan(&ip.ID, &ip.Name, &ip.CreatedAt, &ip.Count); err != nil {
+			return nil, err
+		}
+		providers = append(providers, ip)
+	}
+	return providers, rows.Err()
+}
+
+// GetIdentityProvider 通过ID获取身份提供方
+func GetIdentityProvider(id int) (models.IdentityProvider, error) {
+	var ip models.IdentityProvider
+	err := DB.QueryRow("SELECT id, name, created_at FROM identity_providers WHERE id = ?", id).Scan(&ip.ID, &ip.Name, &ip.CreatedAt)
+	return ip, err
+}
+
+// CreateIdentityProvider 创建身份提供方
+func CreateIdentityProvider(name string) (int64, error) {
+	return createIdentityProvider(DB, name)
+}
+
+func createIdentityProvider(q querier, name string) (int64, error) {
+	var exists int
+	if err := q.QueryRow("SELECT COUNT(*) FROM identity_providers WHERE name = ?", name).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if exists > 0 {
+		return 0, ErrProviderExists
+	}
+
+	result, err := q.Exec("INSERT INTO identity_providers (name, created_at) VALUES (?, ?)", name, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// RenameIdentityProvider 修改身份提供方名称
+func RenameIdentityProvider(id int, name string) error {
+	var exists int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM identity_providers WHERE name = ? AND id != ?", name, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		return ErrProviderExists
+	}
+
+	result, err := DB.Exec("UPDATE identity_providers SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// DeleteIdentityProvider 删除身份提供方，条目上的该登录方式一并移除
+func DeleteIdentityProvider(id int) error {
+	result, err := DB.Exec("DELETE FROM identity_providers WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// ensureIdentityProvider 按名称查找身份提供方，不存在时创建，用于导入
+func ensureIdentityProvider(q querier, name string) (int, error) {
+	var id int
+	err := q.QueryRow("SELECT id FROM identity_providers WHERE name = ?", name).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	newID, err := createIdentityProvider(q, name)
+	return int(newID), err
+}
+
+// legacyProviderID 按旧版auth_logins中的JSON键查找对应的身份提供方，找不到时返回0
+func legacyProviderID(q querier, key string) (int, error) {
+	var id int
+	err := q.QueryRow("SELECT id FROM identity_providers WHERE legacy_key = ?", key).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// setPasswordProviders 替换条目的全部登录方式
+func setPasswordProviders(q querier, passwordID int, links []models.PasswordProvider) error {
+	if _, err := q.Exec("DELETE FROM password_providers WHERE password_id = ?", passwordID); err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		_, err := q.Exec(
+			"INSERT OR REPLACE INTO password_providers (password_id, provider_id, account_id) VALUES (?, ?, ?)",
+			passwordID, link.ProviderID, link.AccountID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachProviders 为条目填充登录方式，没有登录方式的条目得到空数组
+func attachProviders(q querier, passwords []models.Password) error {
+	if len(passwords) == 0 {
+		return nil
+	}
+
+	query := `SELECT pp.password_id, pp.provider_id, ip.name, pp.account_id
+		FROM password_providers pp JOIN identity_providers ip ON ip.id = pp.provider_id`
+	where, args := passwordIDFilter("pp.password_id", passwords)
+	query += where
+	query += " ORDER BY pp.password_id, ip.name"
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	linksByID := make(map[int][]models.PasswordProvider)
+	for rows.Next() {
+		var link models.PasswordProvider
+		var passwordID int
+		var accountID sql.NullInt64
+		if err := rows.Scan(&passwordID, &link.ProviderID, &link.Name, &accountID); err != nil {
+			return err
+		}
+		if accountID.Valid {
+			id := int(accountID.Int64)
+			link.AccountID = &id
+		}
+		linksByID[passwordID] = append(linksByID[passwordID], link)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range passwords {
+		if links, ok := linksByID[passwords[i].ID]; ok {
+			passwords[i].Providers = links
+		} else {
+			passwords[i].Providers = []models.PasswordProvider{}
+		}
+	}
+	return nil
+}
+
+// migrateAuthLogins 将passwords.auth_logins中的JSON布尔值转换为password_providers记录
+func migrateAuthLogins(tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT id, auth_logins FROM passwords WHERE auth_logins IS NOT NULL AND auth_logins != ''")
+	if err != nil {
+		return err
+	}
+
+	logins := make(map[int]map[string]bool)
+	for rows.Next() {
+		var id int
+		var raw string
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return err
+		}
+		var parsed map[string]bool
+		if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+			log.Printf("条目 ID=%d 的授权登录数据无法解析，跳过: %v", id, err)
+			continue
+		}
+		logins[id] = parsed
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, parsed := range logins {
+		for key, enabled := range parsed {
+			if !enabled {
+				continue
+			}
+			providerID, err := legacyProviderID(tx, key)
+			if err != nil {
+				return err
+			}
+			if providerID == 0 {
+				log.Printf("条目 ID=%d 的授权登录 %s 没有对应的身份提供方，跳过", id, key)
+				continue
+			}
+			if _, err := tx.Exec("INSERT OR IGNORE INTO password_providers (password_id, provider_id) VALUES (?, ?)", id, providerID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ProviderExposure 计算身份提供方被盗用时受影响的条目：先找出直接使用该身份提供方登录的条目，
+// 再沿关联的SSO账号逐层查找以已暴露条目为账号登录的条目。每个条目只按最短路径出现一次
+func ProviderExposure(providerID int) ([]models.ExposedEntry, error) {
+	rows, err := DB.Query(`
+		SELECT pp.password_id, pp.provider_id, ip.name, pp.account_id, p.name, p.item_type, p.password = ''
+		FROM password_providers pp
+		JOIN identity_providers ip ON ip.id = pp.provider_id
+		JOIN passwords p ON p.id = pp.password_id AND p.deleted_at IS NULL
+		ORDER BY p.name, pp.password_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var direct []models.ExposedEntry
+	byAccount := make(map[int][]models.ExposedEntry)
+	for rows.Next() {
+		var e models.ExposedEntry
+		var accountID sql.NullInt64
+		if err := rows.Scan(&e.ID, &e.ProviderID, &e.ProviderName, &accountID, &e.Name, &e.ItemType, &e.NoPasswordFallback); err != nil {
+			return nil, err
+		}
+		if accountID.Valid {
+			id := int(accountID.Int64)
+			e.AccountID = &id
+			byAccount[id] = append(byAccount[id], e)
+		}
+		if e.ProviderID == providerID {
+			e.Depth = 1
+			direct = append(direct, e)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	exposed := []models.ExposedEntry{}
+	seen := make(map[int]bool)
+	queue := direct
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+		if seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+		exposed = append(exposed, e)
+
+		for _, next := range byAccount[e.ID] {
+			if !seen[next.ID] {
+				next.Depth = e.Depth + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return exposed, nil
+}
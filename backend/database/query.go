@@ -0,0 +1,396 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// 结构化搜索语句由空白分隔的若干项组成，所有项需同时满足：
+//
+//	site:github.com        网址包含该内容（别名 url:）
+//	tag:work               带有该标签
+//	folder:工作            位于该文件夹或其子文件夹中，folder:none 表示不在任何文件夹中
+//	type:card              条目类型
+//	name: user: notes:     名称、用户名、备注包含该内容
+//	weak:true              密码强度低于 models.WeakPasswordScore，weak:false 为强度足够
+//	strength:<=2           密码强度分数（0-4），支持 < <= > >= =
+//	has:totp               有该项内容，可选值见 hasConditions
+//	is:favorite            收藏、受保护或已过期，可选值见 isConditions
+//	updated:<2024-01-01    修改、创建、最近使用、过期时间（updated、created、used、expires），
+//	                       日期可以是 2024-01-01、2024-01 或 2024，支持 < <= > >= =，省略运算符表示在该时间段内
+//	-tag:archived          在条件前加 - 表示排除
+//	tag:"my work"          值中包含空格时用双引号
+//
+// 其余的文字作为关键词进行全文搜索，带引号的多个词作为一个短语
+
+// QueryError 搜索语句的语法或取值错误
+type QueryError struct {
+	Position int    // 出错的项在语句中的位置（按字符计，从0开始）
+	Token    string // 出错的项
+	Message  string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("第 %d 个字符处的 %s: %s", e.Position+1, e.Token, e.Message)
+}
+
+// SearchQuery 解析后的搜索语句
+type SearchQuery struct {
+	// Terms 全文搜索的关键词，带引号的短语作为一个关键词
+	Terms []string
+	// conditions 筛选条件对应的SQL，均以 AND 开头
+	conditions []string
+	args       []interface{}
+}
+
+// HasFilters 搜索语句中是否有筛选条件
+func (q *SearchQuery) HasFilters() bool {
+	return len(q.conditions) > 0
+}
+
+// Empty 搜索语句中既没有关键词也没有筛选条件
+func (q *SearchQuery) Empty() bool {
+	return len(q.Terms) == 0 && !q.HasFilters()
+}
+
+// queryToken 搜索语句中的一项
+type queryToken struct {
+	pos    int
+	raw    string
+	negate bool
+	key    string // 为空表示关键词
+	value  string
+}
+
+// queryFilter 将筛选条件的值转换为SQL条件，返回的错误信息会附上出错的位置
+type queryFilter func(value string) (string, []interface{}, error)
+
+// queryFilters 支持的筛选条件
+var queryFilters = map[string]queryFilter{
+	"site":     uriFilter,
+	"url":      uriFilter,
+	"tag":      tagFilter,
+	"folder":   folderFilter,
+	"type":     typeFilter,
+	"name":     likeFilter("name"),
+	"user":     likeFilter("username"),
+	"username": likeFilter("username"),
+	"notes":    likeFilter("notes"),
+	"weak":     weakFilter,
+	"strength": strengthFilter,
+	"has":      enumFilter(hasConditions),
+	"is":       enumFilter(isConditions),
+	"created":  dateFilter("created_at"),
+	"updated":  dateFilter("updated_at"),
+	"used":     dateFilter("last_used_at"),
+	"expires":  dateFilter("expires_at"),
+}
+
+// hasConditions has: 支持的值
+var hasConditions = map[string]string{
+	"password":   "password != ''",
+	"username":   "username != ''",
+	"notes":      "notes != ''",
+	"uri":        "id IN (SELECT password_id FROM password_uris)",
+	"url":        "id IN (SELECT password_id FROM password_uris)",
+	"tag":        "id IN (SELECT password_id FROM password_tags)",
+	"field":      "id IN (SELECT password_id FROM password_fields)",
+	"totp":       "id IN (SELECT password_id FROM password_fields WHERE type = '" + models.FieldTypeTOTP + "')",
+	"attachment": "id IN (SELECT password_id FROM attachments)",
+	"expiry":     "(expires_at IS NOT NULL OR rotation_days > 0)",
+}
+
+// isConditions is: 支持的值
+var isConditions = map[string]string{
+	"favorite":  "favorite = 1",
+	"protected": "protected = 1",
+	"expired":   "expires_at IS NOT NULL AND julianday(expires_at) <= julianday('now')",
+}
+
+// ParseSearchQuery 解析结构化搜索语句，语法错误或取值无效时返回 *QueryError
+func ParseSearchQuery(input string) (*SearchQuery, error) {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &SearchQuery{}
+	for _, t := range tokens {
+		if t.key == "" {
+			if t.negate {
+				return nil, &QueryError{Position: t.pos, Token: t.raw, Message: "只能排除筛选条件，不支持排除关键词"}
+			}
+			q.Terms = append(q.Terms, t.value)
+			continue
+		}
+
+		filter, ok := queryFilters[t.key]
+		if !ok {
+			return nil, &QueryError{Position: t.pos, Token: t.raw, Message: unknownKeyMessage(t.key)}
+		}
+		if t.value == "" {
+			return nil, &QueryError{Position: t.pos, Token: t.raw, Message: fmt.Sprintf("%s: 缺少值", t.key)}
+		}
+		cond, args, err := filter(t.value)
+		if err != nil {
+			return nil, &QueryError{Position: t.pos, Token: t.raw, Message: err.Error()}
+		}
+		if t.negate {
+			// 条件涉及的列为NULL时整个条件为NULL，排除时应视为不满足
+			cond = "NOT COALESCE((" + cond + "), 0)"
+		}
+		q.conditions = append(q.conditions, " AND ("+cond+")")
+		q.args = append(q.args, args...)
+	}
+	return q, nil
+}
+
+// tokenizeQuery 将搜索语句拆分为项。key:value 中的key只能由字母组成，
+// 值以 // 开头时（如 https://example.com）整项作为关键词
+func tokenizeQuery(input string) ([]queryToken, error) {
+	runes := []rune(input)
+	var tokens []queryToken
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		t := queryToken{pos: i}
+		start := i
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			t.negate = true
+			i++
+		}
+
+		keyEnd := i
+		for keyEnd < len(runes) && unicode.IsLetter(runes[keyEnd]) && runes[keyEnd] < unicode.MaxASCII {
+			keyEnd++
+		}
+		valueStart := i
+		if keyEnd > i && keyEnd < len(runes) && runes[keyEnd] == ':' &&
+			!(keyEnd+2 < len(runes) && runes[keyEnd+1] == '/' && runes[keyEnd+2] == '/') {
+			t.key = strings.ToLower(string(runes[i:keyEnd]))
+			valueStart = keyEnd + 1
+		}
+
+		i = valueStart
+		if i < len(runes) && runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, &QueryError{Position: i, Token: string(runes[start:]), Message: "缺少结束的双引号"}
+			}
+			t.value = string(runes[i+1 : end])
+			i = end + 1
+			if i < len(runes) && !unicode.IsSpace(runes[i]) {
+				return nil, &QueryError{Position: i, Token: string(runes[start:i]), Message: "结束的双引号后应有空格"}
+			}
+		} else {
+			for i < len(runes) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+			t.value = string(runes[valueStart:i])
+		}
+
+		t.raw = string(runes[start:i])
+		t.value = strings.TrimSpace(t.value)
+		if t.key == "" && t.value == "" {
+			continue
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+// unknownKeyMessage 生成未知筛选条件的提示，名称相近时给出建议
+func unknownKeyMessage(key string) string {
+	keys := make([]string, 0, len(queryFilters))
+	for k := range queryFilters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	msg := fmt.Sprintf("不支持的筛选条件 %s:。", key)
+	best, bestDistance := "", 3
+	for _, k := range keys {
+		if d := editDistance(key, k); d < bestDistance {
+			best, bestDistance = k, d
+		}
+	}
+	if best != "" {
+		msg += fmt.Sprintf("是否要使用 %s:？", best)
+	}
+	msg += "可用的条件: " + strings.Join(keys, ", ") + "。如需搜索包含冒号的文字请加双引号"
+	return msg
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// sortedKeys 返回map中按字母排序的键，用于错误提示
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func uriFilter(value string) (string, []interface{}, error) {
+	return `id IN (SELECT password_id FROM password_uris WHERE uri LIKE ? ESCAPE '\')`,
+		[]interface{}{"%" + escapeLike(value) + "%"}, nil
+}
+
+func tagFilter(value string) (string, []interface{}, error) {
+	return "id IN (SELECT pt.password_id FROM password_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.name = ?)",
+		[]interface{}{value}, nil
+}
+
+func folderFilter(value string) (string, []interface{}, error) {
+	if strings.EqualFold(value, "none") {
+		return "folder_id IS NULL", nil, nil
+	}
+	return `folder_id IN (WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM folders WHERE name = ? COLLATE NOCASE
+			UNION SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id
+		) SELECT id FROM subtree)`, []interface{}{value}, nil
+}
+
+func typeFilter(value string) (string, []interface{}, error) {
+	value = strings.ToLower(value)
+	if _, ok := models.ItemSchemas[value]; !ok {
+		types := make([]string, 0, len(models.ItemSchemas))
+		for t := range models.ItemSchemas {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		return "", nil, fmt.Errorf("不支持的条目类型 %s，可用的类型: %s", value, strings.Join(types, ", "))
+	}
+	return "item_type = ?", []interface{}{value}, nil
+}
+
+// likeFilter 匹配包含该内容的列
+func likeFilter(column string) queryFilter {
+	return func(value string) (string, []interface{}, error) {
+		return column + ` LIKE ? ESCAPE '\'`, []interface{}{"%" + escapeLike(value) + "%"}, nil
+	}
+}
+
+func weakFilter(value string) (string, []interface{}, error) {
+	weak, err := strconv.ParseBool(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("weak: 的值应为 true 或 false")
+	}
+	if weak {
+		return "strength < ?", []interface{}{models.WeakPasswordScore}, nil
+	}
+	return "strength >= ?", []interface{}{models.WeakPasswordScore}, nil
+}
+
+func strengthFilter(value string) (string, []interface{}, error) {
+	op, rest := splitComparison(value)
+	score, err := strconv.Atoi(rest)
+	if err != nil || score < 0 || score > 4 {
+		return "", nil, fmt.Errorf("strength: 的值应为0-4之间的整数，可在前面加 < <= > >= =")
+	}
+	if op == "" {
+		op = "="
+	}
+	return "strength " + op + " ?", []interface{}{score}, nil
+}
+
+// enumFilter 从固定的条件中选择一个
+func enumFilter(conditions map[string]string) queryFilter {
+	return func(value string) (string, []interface{}, error) {
+		cond, ok := conditions[strings.ToLower(value)]
+		if !ok {
+			return "", nil, fmt.Errorf("不支持的值 %s，可用的值: %s", value, strings.Join(sortedKeys(conditions), ", "))
+		}
+		return cond, nil, nil
+	}
+}
+
+// 日期筛选支持的格式及其表示的时间段长度
+var queryDateLayouts = []struct {
+	layout        string
+	years, months int
+	days          int
+}{
+	{"2006-01-02", 0, 0, 1},
+	{"2006-01", 0, 1, 0},
+	{"2006", 1, 0, 0},
+}
+
+// dateFilter 按时间列筛选，日期按服务器时区解析。列为NULL的条目（如从未使用）不满足条件
+func dateFilter(column string) queryFilter {
+	return func(value string) (string, []interface{}, error) {
+		op, rest := splitComparison(value)
+		for _, d := range queryDateLayouts {
+			start, err := time.ParseInLocation(d.layout, rest, time.Local)
+			if err != nil {
+				continue
+			}
+			end := start.AddDate(d.years, d.months, d.days)
+
+			// 时间统一换算为UTC后用julianday比较，避免不同时区偏移的文本比较出错
+			col := "julianday(" + column + ")"
+			switch op {
+			case "<":
+				return col + " < julianday(?)", []interface{}{sqliteTime(start)}, nil
+			case "<=":
+				return col + " < julianday(?)", []interface{}{sqliteTime(end)}, nil
+			case ">":
+				return col + " >= julianday(?)", []interface{}{sqliteTime(end)}, nil
+			case ">=":
+				return col + " >= julianday(?)", []interface{}{sqliteTime(start)}, nil
+			default:
+				return col + " >= julianday(?) AND " + col + " < julianday(?)", []interface{}{sqliteTime(start), sqliteTime(end)}, nil
+			}
+		}
+		return "", nil, fmt.Errorf("无效的日期 %s，格式应为 2024-01-02、2024-01 或 2024，可在前面加 < <= > >= =", rest)
+	}
+}
+
+// splitComparison 拆分值前面的比较运算符
+func splitComparison(value string) (string, string) {
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, strings.TrimSpace(value[len(op):])
+		}
+	}
+	return "", value
+}
+
+// sqliteTime 将时间转换为SQLite日期函数可以解析的UTC时间文本
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
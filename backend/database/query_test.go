@@ -0,0 +1,110 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/007Secret/007Password/models"
+)
+
+func TestTokenizeQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []queryToken
+	}{
+		{"", nil},
+		{"github", []queryToken{{pos: 0, raw: "github", value: "github"}}},
+		{
+			input: `  tag:work -is:favorite "two words"`,
+			want: []queryToken{
+				{pos: 2, raw: "tag:work", key: "tag", value: "work"},
+				{pos: 11, raw: "-is:favorite", negate: true, key: "is", value: "favorite"},
+				{pos: 24, raw: `"two words"`, value: "two words"},
+			},
+		},
+		{`Name:"My Bank"`, []queryToken{{pos: 0, raw: `Name:"My Bank"`, key: "name", value: "My Bank"}}},
+		{"https://example.com", []queryToken{{pos: 0, raw: "https://example.com", value: "https://example.com"}}},
+		{"- a", []queryToken{{pos: 0, raw: "-", value: "-"}, {pos: 2, raw: "a", value: "a"}}},
+		{"邮箱:abc", []queryToken{{pos: 0, raw: "邮箱:abc", value: "邮箱:abc"}}},
+		{`""`, nil},
+	}
+
+	for _, tt := range tests {
+		got, err := tokenizeQuery(tt.input)
+		if err != nil {
+			t.Errorf("tokenizeQuery(%q): %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenizeQuery(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		input      string
+		terms      []string
+		conditions int
+		args       []interface{}
+	}{
+		{"", nil, 0, nil},
+		{"bank card", []string{"bank", "card"}, 0, nil},
+		{`"my bank" site:example.com`, []string{"my bank"}, 1, []interface{}{"%example.com%"}},
+		{"tag:work -tag:old", nil, 2, []interface{}{"work", "old"}},
+		{"is:favorite has:totp weak:true", nil, 3, []interface{}{models.WeakPasswordScore}},
+		{"strength:<=2 type:LOGIN", nil, 2, []interface{}{2, "login"}},
+		{"folder:none name:100%", nil, 2, []interface{}{`%100\%%`}},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseSearchQuery(tt.input)
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q): %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(q.Terms, tt.terms) || len(q.conditions) != tt.conditions || !reflect.DeepEqual(q.args, tt.args) {
+			t.Errorf("ParseSearchQuery(%q) = terms %q, %d conditions, args %v; want %q, %d, %v",
+				tt.input, q.Terms, len(q.conditions), q.args, tt.terms, tt.conditions, tt.args)
+		}
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+		token    string
+	}{
+		{`name:"open`, 5, `name:"open`},
+		{`"a"b`, 3, `"a"`},
+		{"-bank", 0, "-bank"},
+		{"sight:example.com", 0, "sight:example.com"},
+		{"a tag:", 2, "tag:"},
+		{"weak:maybe", 0, "weak:maybe"},
+		{"strength:5", 0, "strength:5"},
+		{"has:pet", 0, "has:pet"},
+		{"type:car", 0, "type:car"},
+		{"updated:yesterday", 0, "updated:yesterday"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseSearchQuery(tt.input)
+		qe, ok := err.(*QueryError)
+		if !ok {
+			t.Errorf("ParseSearchQuery(%q) error = %v, want *QueryError", tt.input, err)
+			continue
+		}
+		if qe.Position != tt.position || qe.Token != tt.token {
+			t.Errorf("ParseSearchQuery(%q) error at %d %q, want %d %q", tt.input, qe.Position, qe.Token, tt.position, tt.token)
+		}
+	}
+}
+
+func TestUnknownKeyMessageSuggestion(t *testing.T) {
+	_, err := ParseSearchQuery("usr:alice")
+	if qe, ok := err.(*QueryError); !ok || !strings.Contains(qe.Message, "user:") {
+		t.Errorf("ParseSearchQuery(usr:alice) error = %v, want a user: suggestion", err)
+	}
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"github.com/007Secret/007Password/models"
+)
+
+// SetPasswordRefs 替换条目字段中的引用关系
+func SetPasswordRefs(passwordID int, refs []models.FieldRef) error {
+	return setPasswordRefs(DB, passwordID, refs)
+}
+
+// setPasswordRefs 替换条目字段中的引用关系
+func setPasswordRefs(q querier, passwordID int, refs []models.FieldRef) error {
+	if _, err := q.Exec("DELETE FROM password_refs WHERE password_id = ?", passwordID); err != nil {
+		return err
+	}
+	for _, r := range refs {
+		if _, err := q.Exec(
+			"INSERT OR IGNORE INTO password_refs (password_id, field, target_id, target_field) VALUES (?, ?, ?, ?)",
+			passwordID, r.Field, r.TargetID, r.TargetField,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRefDependents 获取引用了指定条目的条目，不包括回收站中的条目
+func GetRefDependents(targetID int) ([]models.RefDependent, error) {
+	rows, err := DB.Query(`
+		SELECT p.id, p.name, p.item_type, r.field, r.target_field
+		FROM password_refs r
+		JOIN passwords p ON p.id = r.password_id
+		WHERE r.target_id = ? AND p.deleted_at IS NULL
+		ORDER BY p.name, p.id, r.field
+	`, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dependents := []models.RefDependent{}
+	for rows.Next() {
+		var d models.RefDependent
+		if err := rows.Scan(&d.ID, &d.Name, &d.ItemType, &d.Field, &d.TargetField); err != nil {
+			return nil, err
+		}
+		dependents = append(dependents, d)
+	}
+	return dependents, rows.Err()
+}
+
+// RefReaches 判断从条目from出发沿引用关系能否到达条目to，用于在保存前检测循环引用
+func RefReaches(from, to int) (bool, error) {
+	visited := map[int]bool{from: true}
+	queue := []int{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		rows, err := DB.Query("SELECT DISTINCT target_id FROM password_refs WHERE password_id = ?", id)
+		if err != nil {
+			return false, err
+		}
+		var next []int
+		for rows.Next() {
+			var target int
+			if err := rows.Scan(&target); err != nil {
+				rows.Close()
+				return false, err
+			}
+			next = append(next, target)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return false, err
+		}
+
+		for _, target := range next {
+			if target == to {
+				return true, nil
+			}
+			if !visited[target] {
+				visited[target] = true
+				queue = append(queue, target)
+			}
+		}
+	}
+	return false, nil
+}
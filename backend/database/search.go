@@ -0,0 +1,567 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// 搜索摘要中标记匹配内容的标签
+const (
+	SnippetOpen  = "<mark>"
+	SnippetClose = "</mark>"
+)
+
+// searchIndexReady 全文索引是否可用。SQLite未编译FTS5（构建时未使用 sqlite_fts5 标签）时为false，
+// 搜索退回到LIKE匹配
+var searchIndexReady bool
+
+// 全文索引的列：名称、用户名和手机号、网址、标签、备注、非敏感条目类型字段及自定义字段的名称和值。
+// 密码、敏感字段和敏感的条目类型字段不进入索引
+const searchIndexTableSQL = `CREATE VIRTUAL TABLE IF NOT EXISTS passwords_fts USING fts5(
+	name, username, url, tags, notes, fields,
+	tokenize = 'unicode61 remove_diacritics 2'
+)`
+
+// bm25各列的权重，顺序与searchIndexTableSQL一致
+const searchRank = "bm25(passwords_fts, 10.0, 6.0, 4.0, 3.0, 1.0, 1.0)"
+
+// searchReindexSQL 重建ids（SQL表达式）对应条目的索引内容，回收站中的条目不进入索引
+func searchReindexSQL(ids string) string {
+	return fmt.Sprintf(`DELETE FROM passwords_fts WHERE rowid IN (%[1]s);
+	INSERT INTO passwords_fts (rowid, name, username, url, tags, notes, fields)
+	SELECT p.id, p.name, trim(p.username || ' ' || p.phone),
+		COALESCE((SELECT group_concat(uri, ' ') FROM password_uris WHERE password_id = p.id), ''),
+		COALESCE((SELECT group_concat(t.name, ' ') FROM password_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.password_id = p.id), ''),
+		p.notes,
+		trim(p.item_search || ' ' || COALESCE((SELECT group_concat(CASE WHEN type IN ('%[2]s', '%[3]s', '%[4]s', '%[5]s') THEN name || ' ' || value ELSE name END, ' ')
+			FROM password_fields WHERE password_id = p.id), ''))
+	FROM passwords p WHERE p.id IN (%[1]s) AND p.deleted_at IS NULL;`,
+		ids, models.FieldTypeText, models.FieldTypeURL, models.FieldTypeEmail, models.FieldTypeDate)
+}
+
+// searchTriggers 保持全文索引与条目及其网址、标签、自定义字段同步的触发器
+var searchTriggers = map[string]string{
+	"passwords_fts_ai":       "AFTER INSERT ON passwords BEGIN " + searchReindexSQL("NEW.id") + " END",
+	"passwords_fts_au":       "AFTER UPDATE OF name, username, phone, notes, item_search, deleted_at ON passwords BEGIN " + searchReindexSQL("NEW.id") + " END",
+	"passwords_fts_ad":       "AFTER DELETE ON passwords BEGIN DELETE FROM passwords_fts WHERE rowid = OLD.id; END",
+	"password_uris_fts_ai":   "AFTER INSERT ON password_uris BEGIN " + searchReindexSQL("NEW.password_id") + " END",
+	"password_uris_fts_au":   "AFTER UPDATE ON password_uris BEGIN " + searchReindexSQL("NEW.password_id") + " END",
+	"password_uris_fts_ad":   "AFTER DELETE ON password_uris BEGIN " + searchReindexSQL("OLD.password_id") + " END",
+	"password_tags_fts_ai":   "AFTER INSERT ON password_tags BEGIN " + searchReindexSQL("NEW.password_id") + " END",
+	"password_tags_fts_ad":   "AFTER DELETE ON password_tags BEGIN " + searchReindexSQL("OLD.password_id") + " END",
+	"tags_fts_au":            "AFTER UPDATE OF name ON tags BEGIN " + searchReindexSQL("SELECT password_id FROM password_tags WHERE tag_id = NEW.id") + " END",
+	"password_fields_fts_ai": "AFTER INSERT ON password_fields BEGIN " + searchReindexSQL("NEW.password_id") + " END",
+	"password_fields_fts_au": "AFTER UPDATE ON password_fields BEGIN " + searchReindexSQL("NEW.password_id") + " END",
+	"password_fields_fts_ad": "AFTER DELETE ON password_fields BEGIN " + searchReindexSQL("OLD.password_id") + " END",
+}
+
+// ensureSearchIndex 在SQLite支持FTS5时创建全文索引和同步触发器，触发器不完整时重建整个索引。
+// 不支持FTS5时删除触发器，避免写入条目时因缺少fts5模块而失败
+func ensureSearchIndex() error {
+	searchIndexReady = false
+
+	var available bool
+	if err := DB.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&available); err != nil {
+		return err
+	}
+
+	if !available {
+		log.Printf("SQLite未启用FTS5，搜索使用LIKE匹配")
+		for name := range searchTriggers {
+			if _, err := DB.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	complete, err := searchTriggersComplete()
+	if err != nil {
+		return err
+	}
+	if !complete {
+		log.Printf("创建全文索引")
+		err := withTx(func(tx *sql.Tx) error {
+			if _, err := tx.Exec(searchIndexTableSQL); err != nil {
+				return err
+			}
+			for name, body := range searchTriggers {
+				if _, err := tx.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+					return err
+				}
+				if _, err := tx.Exec("CREATE TRIGGER " + name + " " + body); err != nil {
+					return fmt.Errorf("创建触发器 %s 失败: %w", name, err)
+				}
+			}
+			if _, err := tx.Exec("DELETE FROM passwords_fts"); err != nil {
+				return err
+			}
+			_, err := tx.Exec(searchReindexSQL("SELECT id FROM passwords"))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	searchIndexReady = true
+	return nil
+}
+
+// searchTriggersComplete 检查全文索引的触发器是否都存在且与当前定义一致。
+// 不支持FTS5的版本运行期间会删除触发器，此时索引已过期，需要重建；索引内容的定义变化时同样需要重建
+func searchTriggersComplete() (bool, error) {
+	rows, err := DB.Query("SELECT name, sql FROM sqlite_master WHERE type = 'trigger'")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	found := 0
+	for rows.Next() {
+		var name, stmt string
+		if err := rows.Scan(&name, &stmt); err != nil {
+			return false, err
+		}
+		if body, ok := searchTriggers[name]; ok && stmt == "CREATE TRIGGER "+name+" "+body {
+			found++
+		}
+	}
+	return found == len(searchTriggers), rows.Err()
+}
+
+// ftsQuery 将关键词转换为FTS5查询：每个关键词按前缀匹配，多个关键词需同时匹配，
+// 短语中的词需按顺序相邻。关键词中的引号、括号和运算符都按普通文字处理
+func ftsQuery(terms []string) string {
+	var parts []string
+	for _, term := range terms {
+		if !strings.ContainsFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+			continue
+		}
+		parts = append(parts, `"`+strings.ReplaceAll(term, `"`, `""`)+`"*`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// SearchOptions 搜索选项
+type SearchOptions struct {
+	// Limit 结果数量，不大于0时不限制
+	Limit int
+	// Fuzzy 精确匹配数量不足时是否补充模糊匹配的条目。智能文件夹等需要确定结果集的场景不开启
+	Fuzzy bool
+}
+
+// SearchPasswords 按结构化搜索语句查找条目，同时满足filter中的其他筛选条件，不包括回收站中的条目。
+// 关键词在名称、用户名、网址、标签、备注和非敏感自定义字段中搜索，依次查找全文索引命中的条目、
+// 全文索引未命中但包含关键词的条目（如中文词语中间的部分），数量不足时再补充模糊匹配的条目
+// （拼写错误、跳字和名称的拼音首字母，需开启opts.Fuzzy）。结果按相关度结合使用次数排序，模糊匹配的条目排在精确匹配之后；
+// 没有关键词时按filter.Sort排序，未指定时按名称
+func SearchPasswords(filter PasswordFilter, query *SearchQuery, opts SearchOptions) ([]models.SearchResult, error) {
+	if query.Empty() {
+		return []models.SearchResult{}, nil
+	}
+	conditions, args, err := filterConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	conditions += strings.Join(query.conditions, "")
+	args = append(args, query.args...)
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite中负数的LIMIT表示不限制
+	}
+
+	if len(query.Terms) == 0 {
+		sort := filter.Sort
+		if sort == "" {
+			sort = SortName
+		}
+		return querySearchResults("SELECT "+passwordColumns+", '', 0 FROM passwords WHERE deleted_at IS NULL"+conditions+orderClause(sortKeysFor(sort))+" LIMIT ?", append(args, limit)...)
+	}
+
+	results := []models.SearchResult{}
+	if match := ftsQuery(query.Terms); searchIndexReady && match != "" {
+		q := "SELECT " + passwordColumns + ", s.snippet, s.score FROM passwords JOIN (" +
+			"SELECT rowid AS fts_id, snippet(passwords_fts, -1, '" + SnippetOpen + "', '" + SnippetClose + "', '…', 12) AS snippet, " +
+			"-" + searchRank + " AS score FROM passwords_fts WHERE passwords_fts MATCH ?" +
+			") s ON s.fts_id = passwords.id WHERE deleted_at IS NULL" + conditions + " ORDER BY s.score DESC, id LIMIT ?"
+		results, err = querySearchResults(q, append(append([]interface{}{match}, args...), limit)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 补充包含所有关键词的条目，已命中全文索引的条目不重复返回
+	if limit < 0 || len(results) < limit {
+		extra, err := likeSearch(conditions, args, query.Terms, results, remaining(limit, results))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, extra...)
+	}
+	exactRelevance(results)
+
+	// 仍不足时补充模糊匹配的条目，如拼写错误和拼音首字母
+	if opts.Fuzzy && (limit < 0 || len(results) < limit) {
+		extra, err := fuzzySearch(conditions, args, query.Terms, results, remaining(limit, results))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, extra...)
+	}
+
+	// 相关度结合使用次数排序，常用的条目排在前面
+	for i := range results {
+		results[i].Score *= usageBoost(results[i].UseCount)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results, nil
+}
+
+// remaining 返回还可以补充的结果数量，limit为负数表示不限制
+func remaining(limit int, results []models.SearchResult) int {
+	if limit < 0 {
+		return limit
+	}
+	return limit - len(results)
+}
+
+// excludeResults 生成排除已有结果的SQL条件
+func excludeResults(results []models.SearchResult) (string, []interface{}) {
+	if len(results) == 0 {
+		return "", nil
+	}
+	args := make([]interface{}, len(results))
+	for i, r := range results {
+		args[i] = r.ID
+	}
+	return " AND id NOT IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(results)), ", ") + ")", args
+}
+
+// CountSearchResults 统计SearchPasswords精确匹配（全文索引或包含所有关键词）的条目数量，不含模糊匹配
+func CountSearchResults(filter PasswordFilter, query *SearchQuery) (int, error) {
+	if query.Empty() {
+		return 0, nil
+	}
+	filter.Search = query
+	conditions, args, err := filterConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = DB.QueryRow("SELECT COUNT(*) FROM passwords WHERE deleted_at IS NULL"+conditions, args...).Scan(&count)
+	return count, err
+}
+
+// exactConditions 生成只保留精确匹配搜索语句的条目的SQL条件，以 AND 开头：满足所有筛选条件，
+// 且命中全文索引或包含所有关键词。两种匹配在同一个条件中按ID合并，不需要读取条目内容
+func (q *SearchQuery) exactConditions() (string, []interface{}) {
+	conditions := strings.Join(q.conditions, "")
+	args := append([]interface{}{}, q.args...)
+	if len(q.Terms) == 0 {
+		return conditions, args
+	}
+
+	like, likeArgs := likeTermsCondition(q.Terms)
+	if match := ftsQuery(q.Terms); searchIndexReady && match != "" {
+		conditions += " AND (id IN (SELECT rowid FROM passwords_fts WHERE passwords_fts MATCH ?) OR (" + like + "))"
+		args = append(append(args, match), likeArgs...)
+	} else {
+		conditions += " AND " + like
+		args = append(args, likeArgs...)
+	}
+	return conditions, args
+}
+
+// likeTermsCondition 生成要求包含所有关键词的LIKE条件
+func likeTermsCondition(terms []string) (string, []interface{}) {
+	parts := make([]string, len(terms))
+	var args []interface{}
+	for i, term := range terms {
+		parts[i] = "(" + likeSearchCondition + ")"
+		like := "%" + escapeLike(term) + "%"
+		for j := 0; j < likeSearchParams; j++ {
+			args = append(args, like)
+		}
+	}
+	return strings.Join(parts, " AND "), args
+}
+
+// likeSearch 查找包含所有关键词的条目，按名称排序
+func likeSearch(conditions string, args []interface{}, terms []string, exclude []models.SearchResult, limit int) ([]models.SearchResult, error) {
+	like, likeArgs := likeTermsCondition(terms)
+	q := "SELECT " + passwordColumns + ", '', 0 FROM passwords WHERE deleted_at IS NULL" + conditions + " AND " + like
+	args = append(append([]interface{}{}, args...), likeArgs...)
+	excluded, excludedArgs := excludeResults(exclude)
+	q += excluded + " ORDER BY name COLLATE NOCASE, id LIMIT ?"
+	results, err := querySearchResults(q, append(append(args, excludedArgs...), limit)...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		for _, term := range terms {
+			if results[i].Snippet = likeSnippet(results[i].Password, term); results[i].Snippet != "" {
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
+// exactRelevance 将全文索引和LIKE匹配的结果换算为1-2之间的相关度：全文索引的结果按bm25与最高分之比，
+// LIKE匹配的结果为1。模糊匹配的相关度不超过fuzzyRelevance，即使加上使用次数的加成也排在精确匹配之后
+func exactRelevance(results []models.SearchResult) {
+	var top float64
+	for _, r := range results {
+		top = math.Max(top, r.Score)
+	}
+	for i := range results {
+		if top > 0 {
+			results[i].Score = 1 + results[i].Score/top
+		} else {
+			results[i].Score = 1
+		}
+	}
+}
+
+// fuzzyRelevance 模糊匹配得分为1时的相关度
+const fuzzyRelevance = 0.5
+
+// fuzzySearch 对满足筛选条件的其余条目做模糊匹配，返回得分最高的limit个条目
+func fuzzySearch(conditions string, args []interface{}, terms []string, exclude []models.SearchResult, limit int) ([]models.SearchResult, error) {
+	excluded, excludedArgs := excludeResults(exclude)
+	rows, err := DB.Query("SELECT id, name, username, use_count FROM passwords WHERE deleted_at IS NULL"+conditions+excluded,
+		append(append([]interface{}{}, args...), excludedArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make(map[int]*fuzzyCandidate)
+	for rows.Next() {
+		c := &fuzzyCandidate{}
+		if err := rows.Scan(&c.id, &c.name, &c.username, &c.useCount); err != nil {
+			return nil, err
+		}
+		c.initials = nameInitials(c.name)
+		candidates[c.id] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if len(candidates) == 0 {
+		return []models.SearchResult{}, nil
+	}
+	if err := attachFuzzyRelations(candidates); err != nil {
+		return nil, err
+	}
+
+	type hit struct {
+		id    int
+		score float64
+		match fuzzyMatch
+		rank  float64
+	}
+	var hits []hit
+	for _, c := range candidates {
+		if score, match := fuzzyScore(*c, terms); score > 0 {
+			hits = append(hits, hit{id: c.id, score: score * fuzzyRelevance, match: match, rank: score * usageBoost(c.useCount)})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].rank != hits[j].rank {
+			return hits[i].rank > hits[j].rank
+		}
+		return hits[i].id < hits[j].id
+	})
+	if limit >= 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	if len(hits) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	ids := make([]interface{}, len(hits))
+	byID := make(map[int]hit, len(hits))
+	for i, h := range hits {
+		ids[i] = h.id
+		byID[h.id] = h
+	}
+	results, err := querySearchResults("SELECT "+passwordColumns+", '', 0 FROM passwords WHERE id IN ("+
+		strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")+")", ids...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		h := byID[results[i].ID]
+		results[i].Score = h.score
+		results[i].Snippet = fuzzySnippet(h.match)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return byID[results[i].ID].rank > byID[results[j].ID].rank })
+	return results, nil
+}
+
+// attachFuzzyRelations 为模糊匹配的候选条目填充网址的主机名和标签
+func attachFuzzyRelations(candidates map[int]*fuzzyCandidate) error {
+	rows, err := DB.Query("SELECT password_id, uri FROM password_uris ORDER BY position")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var uri string
+		if err := rows.Scan(&id, &uri); err != nil {
+			return err
+		}
+		if c, ok := candidates[id]; ok {
+			c.hosts = append(c.hosts, uriHost(uri))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	tagRows, err := DB.Query("SELECT pt.password_id, t.name FROM password_tags pt JOIN tags t ON t.id = pt.tag_id")
+	if err != nil {
+		return err
+	}
+	defer tagRows.Close()
+	for tagRows.Next() {
+		var id int
+		var name string
+		if err := tagRows.Scan(&id, &name); err != nil {
+			return err
+		}
+		if c, ok := candidates[id]; ok {
+			c.tags = append(c.tags, name)
+		}
+	}
+	return tagRows.Err()
+}
+
+// likeSearchCondition 与全文索引相同范围的LIKE匹配条件，参数为likeSearchParams个相同的匹配模式
+const likeSearchParams = 9
+
+const likeSearchCondition = `name LIKE ? ESCAPE '\' OR username LIKE ? ESCAPE '\' OR phone LIKE ? ESCAPE '\' OR notes LIKE ? ESCAPE '\' OR item_search LIKE ? ESCAPE '\'
+	OR id IN (SELECT password_id FROM password_uris WHERE uri LIKE ? ESCAPE '\')
+	OR id IN (SELECT pt.password_id FROM password_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.name LIKE ? ESCAPE '\')
+	OR id IN (SELECT password_id FROM password_fields WHERE name LIKE ? ESCAPE '\'
+		OR (type IN ('` + models.FieldTypeText + `', '` + models.FieldTypeURL + `', '` + models.FieldTypeEmail + `', '` + models.FieldTypeDate + `') AND value LIKE ? ESCAPE '\'))`
+
+// escapeLike 转义LIKE模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// querySearchResults 查询条目及其摘要和相关度，查询的最后两列为摘要和相关度
+func querySearchResults(query string, args ...interface{}) ([]models.SearchResult, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	var passwords []models.Password
+	for rows.Next() {
+		var r models.SearchResult
+		p, err := scanPassword(extraScanner{row: rows, extra: []interface{}{&r.Snippet, &r.Score}})
+		if err != nil {
+			return nil, err
+		}
+		r.Password = p
+		results = append(results, r)
+		passwords = append(passwords, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := attachRelations(passwords); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Password = passwords[i]
+	}
+	return results, nil
+}
+
+// extraScanner 在scanPassword读取的列之后继续读取额外的列
+type extraScanner struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// likeSnippet 为LIKE匹配到的条目生成摘要，标记第一个包含关键词的字段中的匹配位置
+func likeSnippet(p models.Password, keyword string) string {
+	candidates := []string{p.Name, p.Username, p.Phone}
+	for _, u := range p.URIs {
+		candidates = append(candidates, u.URI)
+	}
+	candidates = append(candidates, p.Notes, p.ItemSearch)
+	candidates = append(candidates, p.Tags...)
+	for _, f := range p.Fields {
+		candidates = append(candidates, f.Name)
+		if !f.IsSecret() {
+			candidates = append(candidates, f.Value)
+		}
+	}
+
+	lowerKeyword := []rune(strings.ToLower(keyword))
+	for _, text := range candidates {
+		runes := []rune(text)
+		lower := []rune(strings.ToLower(text))
+		if len(lower) != len(runes) {
+			continue
+		}
+		at := runeIndex(lower, lowerKeyword)
+		if at < 0 {
+			continue
+		}
+
+		// 匹配位置前后各保留一段上下文
+		const context = 20
+		start, end := at-context, at+len(lowerKeyword)+context
+		prefix, suffix := "…", "…"
+		if start <= 0 {
+			start, prefix = 0, ""
+		}
+		if end >= len(runes) {
+			end, suffix = len(runes), ""
+		}
+		return prefix + string(runes[start:at]) + SnippetOpen + string(runes[at:at+len(lowerKeyword)]) + SnippetClose + string(runes[at+len(lowerKeyword):end]) + suffix
+	}
+	return ""
+}
+
+// runeIndex 返回sub在s中第一次出现的位置，不存在时返回-1
+func runeIndex(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
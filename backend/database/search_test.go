@@ -0,0 +1,161 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+// openSearchTestDB 创建带有几条测试条目的数据库，编译了FTS5时同时建立全文索引
+func openSearchTestDB(t *testing.T) {
+	t.Helper()
+	openTestDB(t)
+	if err := ensureSearchIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := []string{
+		"INSERT INTO passwords (id, name, username) VALUES (1, 'GitHub', 'alice')",
+		"INSERT INTO passwords (id, name, username, notes) VALUES (2, 'GitLab', 'bob', 'mirror of github')",
+		"INSERT INTO passwords (id, name, notes) VALUES (3, '银行卡', '招商银行')",
+		"INSERT INTO passwords (id, name, deleted_at) VALUES (4, 'GitHub Bank', CURRENT_TIMESTAMP)",
+		"INSERT INTO password_uris (password_id, uri) VALUES (1, 'https://github.com')",
+		"INSERT INTO tags (id, name) VALUES (1, 'work')",
+		"INSERT INTO password_tags (password_id, tag_id) VALUES (1, 1), (2, 1)",
+	}
+	for _, stmt := range statements {
+		if _, err := DB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+}
+
+func TestCountSearchResults(t *testing.T) {
+	openSearchTestDB(t)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"github", 2},
+		{"git", 2},
+		{"商银", 1},
+		{"github tag:work", 2},
+		{"tag:work", 2},
+		{"github -tag:work", 0},
+		{"bank", 0},
+		{"gihtub", 0}, // 模糊匹配不计入数量
+		{"site:github.com alice", 1},
+	}
+
+	for _, tt := range tests {
+		query, err := ParseSearchQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := CountSearchResults(PasswordFilter{}, query)
+		if err != nil {
+			t.Errorf("CountSearchResults(%q): %v", tt.query, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CountSearchResults(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+// 非敏感的条目类型字段保存在item_search中，可以被搜索到
+func TestSearchItemFields(t *testing.T) {
+	openSearchTestDB(t)
+	if _, err := DB.Exec("INSERT INTO passwords (id, item_type, name, item_search) VALUES (5, 'bank_account', '工资卡', 'Chase CHASUS33')"); err != nil {
+		t.Fatal(err)
+	}
+
+	query, err := ParseSearchQuery("chase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CountSearchResults(PasswordFilter{}, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Errorf("CountSearchResults(chase) = %d, want 1", got)
+	}
+}
+
+// 按智能文件夹分页：只包含精确匹配的条目，总数与CountSearchResults一致
+func TestListPasswordsPageSearch(t *testing.T) {
+	openSearchTestDB(t)
+
+	for _, tt := range []struct {
+		query string
+		ids   []int
+	}{
+		{"github", []int{1, 2}},
+		{"gihtub", nil},
+		{"tag:work -name:lab", []int{1}},
+	} {
+		query, err := ParseSearchQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []int
+		cursor := ""
+		for {
+			page, err := ListPasswordsPage(PasswordFilter{Search: query, Sort: SortName}, 1, cursor)
+			if err != nil {
+				t.Fatalf("%q: %v", tt.query, err)
+			}
+			if page.Total != len(tt.ids) {
+				t.Errorf("%q: total = %d, want %d", tt.query, page.Total, len(tt.ids))
+			}
+			for _, p := range page.Passwords {
+				ids = append(ids, p.ID)
+			}
+			if cursor = page.NextCursor; cursor == "" {
+				break
+			}
+		}
+		if !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("%q: ids = %v, want %v", tt.query, ids, tt.ids)
+		}
+	}
+}
+
+// 触发器定义与当前版本不一致时重建触发器和索引
+func TestEnsureSearchIndexRebuildsOutdatedTriggers(t *testing.T) {
+	openSearchTestDB(t)
+	if !searchIndexReady {
+		t.Skip("SQLite未启用FTS5，需使用 -tags sqlite_fts5 运行")
+	}
+
+	statements := []string{
+		"DROP TRIGGER passwords_fts_au",
+		"CREATE TRIGGER passwords_fts_au AFTER UPDATE OF name ON passwords BEGIN SELECT 1; END",
+		"DELETE FROM passwords_fts",
+	}
+	for _, stmt := range statements {
+		if _, err := DB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if complete, err := searchTriggersComplete(); err != nil || complete {
+		t.Fatalf("searchTriggersComplete() = %v, %v; want false", complete, err)
+	}
+	if err := ensureSearchIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if complete, err := searchTriggersComplete(); err != nil || !complete {
+		t.Errorf("after rebuild searchTriggersComplete() = %v, %v; want true", complete, err)
+	}
+
+	var indexed int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM passwords_fts").Scan(&indexed); err != nil {
+		t.Fatal(err)
+	}
+	if indexed != 3 {
+		t.Errorf("indexed %d entries after rebuild, want 3", indexed)
+	}
+}
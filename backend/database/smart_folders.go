@@ -0,0 +1,97 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// ErrSmartFolderExists 智能文件夹名称已被使用
+var ErrSmartFolderExists = errors.New("smart folder already exists")
+
+// GetAllSmartFolders 获取所有智能文件夹及当前匹配的条目数量（不含回收站）
+func GetAllSmartFolders() ([]models.SmartFolder, error) {
+	rows, err := DB.Query("SELECT id, name, query, created_at, updated_at FROM smart_folders ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := []models.SmartFolder{}
+	for rows.Next() {
+		var f models.SmartFolder
+		if err := rows.Scan(&f.ID, &f.Name, &f.Query, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		folders = append(folders, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range folders {
+		// 保存时已校验过语句，解析失败说明语法已变化，数量保持为0
+		query, err := ParseSearchQuery(folders[i].Query)
+		if err != nil {
+			continue
+		}
+		if folders[i].Count, err = CountSearchResults(PasswordFilter{}, query); err != nil {
+			return nil, err
+		}
+	}
+	return folders, nil
+}
+
+// GetSmartFolderByID 通过ID获取智能文件夹，不计算条目数量
+func GetSmartFolderByID(id int) (models.SmartFolder, error) {
+	var f models.SmartFolder
+	err := DB.QueryRow("SELECT id, name, query, created_at, updated_at FROM smart_folders WHERE id = ?", id).
+		Scan(&f.ID, &f.Name, &f.Query, &f.CreatedAt, &f.UpdatedAt)
+	return f, err
+}
+
+// CreateSmartFolder 创建智能文件夹，query应已通过ParseSearchQuery校验
+func CreateSmartFolder(name, query string) (int64, error) {
+	var exists bool
+	if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM smart_folders WHERE name = ?)", name).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, ErrSmartFolderExists
+	}
+
+	now := time.Now()
+	result, err := DB.Exec("INSERT INTO smart_folders (name, query, created_at, updated_at) VALUES (?, ?, ?, ?)", name, query, now, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateSmartFolder 修改智能文件夹的名称和搜索语句
+func UpdateSmartFolder(id int, name, query string) error {
+	var exists bool
+	if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM smart_folders WHERE name = ? AND id != ?)", name, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrSmartFolderExists
+	}
+
+	result, err := DB.Exec("UPDATE smart_folders SET name = ?, query = ?, updated_at = ? WHERE id = ?", name, query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// DeleteSmartFolder 删除智能文件夹，不影响其中的条目
+func DeleteSmartFolder(id int) error {
+	result, err := DB.Exec("DELETE FROM smart_folders WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
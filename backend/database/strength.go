@@ -0,0 +1,28 @@
+package database
+
+// SetPasswordStrength 保存条目密码的强度分数，score为nil表示无法评估
+func SetPasswordStrength(id int, score *int) error {
+	_, err := DB.Exec("UPDATE passwords SET strength = ? WHERE id = ?", score, id)
+	return err
+}
+
+// PasswordsWithoutStrength 返回有密码但还没有强度分数的条目ID及其加密的密码，
+// 包括升级前创建的条目和恢复过历史密码的条目
+func PasswordsWithoutStrength() (map[int]string, error) {
+	rows, err := DB.Query("SELECT id, password FROM passwords WHERE strength IS NULL AND password != ''")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	passwords := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var encrypted string
+		if err := rows.Scan(&id, &encrypted); err != nil {
+			return nil, err
+		}
+		passwords[id] = encrypted
+	}
+	return passwords, rows.Err()
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// ErrTagExists 标签名称已被使用
+var ErrTagExists = errors.New("tag already exists")
+
+// normalizeTags 去除标签名称两端空白，忽略空名称和重复名称（不区分大小写）
+func normalizeTags(names []string) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, name)
+	}
+	return tags
+}
+
+// setPasswordTags 替换条目的全部标签，不存在的标签会自动创建
+func setPasswordTags(q querier, passwordID int, names []string) error {
+	if _, err := q.Exec("DELETE FROM password_tags WHERE password_id = ?", passwordID); err != nil {
+		return err
+	}
+
+	for _, name := range normalizeTags(names) {
+		if _, err := q.Exec("INSERT INTO tags (name, created_at) VALUES (?, ?) ON CONFLICT(name) DO NOTHING", name, time.Now()); err != nil {
+			return err
+		}
+		_, err := q.Exec(
+			"INSERT OR IGNORE INTO password_tags (password_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			passwordID, name,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachTags 为条目填充标签名称，没有标签的条目得到空数组
+func attachTags(q querier, passwords []models.Password) error {
+	if len(passwords) == 0 {
+		return nil
+	}
+
+	query := "SELECT pt.password_id, t.name FROM password_tags pt JOIN tags t ON t.id = pt.tag_id"
+	where, args := passwordIDFilter("pt.password_id", passwords)
+	query += where
+	query += " ORDER BY t.name"
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	tagsByID := make(map[int][]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return err
+		}
+		tagsByID[id] = append(tagsByID[id], name)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range passwords {
+		if tags, ok := tagsByID[passwords[i].ID]; ok {
+			passwords[i].Tags = tags
+		} else {
+			passwords[i].Tags = []string{}
+		}
+	}
+	return nil
+}
+
+// GetAllTags 获取所有标签及使用该标签的条目数量（不含回收站）
+func GetAllTags() ([]models.Tag, error) {
+	rows, err := DB.Query(`
+		SELECT t.id, t.name, t.created_at, COUNT(p.id)
+		FROM tags t
+		LEFT JOIN password_tags pt ON pt.tag_id = t.id
+		LEFT JOIN passwords p ON p.id = pt.password_id AND p.deleted_at IS NULL
+		GROUP BY t.id
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.CreatedAt, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// CreateTag 创建标签
+func CreateTag(name string) (int64, error) {
+	var exists int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM tags WHERE name = ?", name).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if exists > 0 {
+		return 0, ErrTagExists
+	}
+
+	result, err := DB.Exec("INSERT INTO tags (name, created_at) VALUES (?, ?)", name, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// RenameTag 修改标签名称
+func RenameTag(id int, name string) error {
+	var exists int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM tags WHERE name = ? AND id != ?", name, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		return ErrTagExists
+	}
+
+	result, err := DB.Exec("UPDATE tags SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// DeleteTag 删除标签，条目上的该标签一并移除
+func DeleteTag(id int) error {
+	result, err := DB.Exec("DELETE FROM tags WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
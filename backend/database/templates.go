@@ -0,0 +1,177 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// ErrTemplateExists 模板名称已被使用
+var ErrTemplateExists = errors.New("template already exists")
+
+// GetTemplates 获取所有模板及其字段定义，按名称排序
+func GetTemplates() ([]models.Template, error) {
+	rows, err := DB.Query("SELECT id, name, description, item_type, created_at, updated_at FROM templates ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []models.Template{}
+	for rows.Next() {
+		var t models.Template
+		if err := rows.Scan(&t.ID, &t.Name, &t.Description, &t.ItemType, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range templates {
+		fields, err := getTemplateFields(templates[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		templates[i].Fields = fields
+	}
+	return templates, nil
+}
+
+// GetTemplate 通过ID获取模板及其字段定义
+func GetTemplate(id int) (models.Template, error) {
+	var t models.Template
+	err := DB.QueryRow("SELECT id, name, description, item_type, created_at, updated_at FROM templates WHERE id = ?", id).
+		Scan(&t.ID, &t.Name, &t.Description, &t.ItemType, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return t, err
+	}
+	t.Fields, err = getTemplateFields(id)
+	return t, err
+}
+
+// getTemplateFields 按定义顺序获取模板的字段
+func getTemplateFields(templateID int) ([]models.TemplateField, error) {
+	rows, err := DB.Query(
+		"SELECT name, type, target, required, default_value, generator FROM template_fields WHERE template_id = ? ORDER BY position",
+		templateID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []models.TemplateField{}
+	for rows.Next() {
+		var f models.TemplateField
+		var generator string
+		if err := rows.Scan(&f.Name, &f.Type, &f.Target, &f.Required, &f.Default, &generator); err != nil {
+			return nil, err
+		}
+		if generator != "" {
+			var rule models.GeneratorRule
+			if err := json.Unmarshal([]byte(generator), &rule); err != nil {
+				return nil, err
+			}
+			f.Generator = &rule
+		}
+		fields = append(fields, f)
+	}
+	return fields, rows.Err()
+}
+
+// CreateTemplate 创建模板
+func CreateTemplate(t models.Template) (int64, error) {
+	var id int64
+	err := withTx(func(tx *sql.Tx) error {
+		if err := checkTemplateName(tx, t.Name, 0); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result, err := tx.Exec(
+			"INSERT INTO templates (name, description, item_type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+			t.Name, t.Description, t.ItemType, now, now,
+		)
+		if err != nil {
+			return err
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		return setTemplateFields(tx, int(id), t.Fields)
+	})
+	return id, err
+}
+
+// UpdateTemplate 修改模板，字段定义整体替换
+func UpdateTemplate(t models.Template) error {
+	return withTx(func(tx *sql.Tx) error {
+		if err := checkTemplateName(tx, t.Name, t.ID); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(
+			"UPDATE templates SET name = ?, description = ?, item_type = ?, updated_at = ? WHERE id = ?",
+			t.Name, t.Description, t.ItemType, time.Now(), t.ID,
+		)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		return setTemplateFields(tx, t.ID, t.Fields)
+	})
+}
+
+// DeleteTemplate 删除模板，已从模板创建的条目不受影响
+func DeleteTemplate(id int) error {
+	result, err := DB.Exec("DELETE FROM templates WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// checkTemplateName 检查模板名称是否已被其他模板使用
+func checkTemplateName(q querier, name string, selfID int) error {
+	var exists int
+	if err := q.QueryRow("SELECT COUNT(*) FROM templates WHERE name = ? AND id != ?", name, selfID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		return ErrTemplateExists
+	}
+	return nil
+}
+
+// setTemplateFields 替换模板的字段定义
+func setTemplateFields(q querier, templateID int, fields []models.TemplateField) error {
+	if _, err := q.Exec("DELETE FROM template_fields WHERE template_id = ?", templateID); err != nil {
+		return err
+	}
+
+	for i, f := range fields {
+		var generator string
+		if f.Generator != nil {
+			data, err := json.Marshal(f.Generator)
+			if err != nil {
+				return err
+			}
+			generator = string(data)
+		}
+		if _, err := q.Exec(
+			"INSERT INTO template_fields (template_id, position, name, type, target, required, default_value, generator) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			templateID, i, f.Name, f.Type, f.Target, f.Required, f.Default, generator,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
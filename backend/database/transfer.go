@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// RefRewriter 将导入条目字段引用中的导出文件条目ID替换为导入后的新ID，并设置p.Refs，
+// 返回条目是否被修改。p的敏感内容为密文，由调用方负责解密和重新加密
+type RefRewriter func(p *models.Password, newIDs map[int]int) (bool, error)
+
+// ImportPasswords 在一个事务中导入条目，任何一条失败都会整体回滚。
+// 条目的敏感内容需已由调用方加密，FolderPath对应的文件夹不存在时自动创建
+func ImportPasswords(items []models.ExportItem, rewriteRefs RefRewriter) (int, error) {
+	err := withTx(func(tx *sql.Tx) error {
+		// 导出文件中的条目ID到新条目ID的映射，用于还原登录方式关联的SSO账号和字段引用
+		newIDs := make(map[int]int, len(items))
+		created := make([]int, len(items))
+		saved := make([]models.Password, len(items))
+
+		for i, item := range items {
+			p := item.Password
+			p.FolderID = nil
+			p.Providers = nil
+			if item.FolderPath != "" {
+				folderID, err := ensureFolderPath(tx, item.FolderPath)
+				if err != nil {
+					return err
+				}
+				p.FolderID = folderID
+			}
+
+			id, err := createPassword(tx, p)
+			if err != nil {
+				return err
+			}
+			created[i] = int(id)
+			p.ID = int(id)
+			saved[i] = p
+			if item.ID != 0 {
+				newIDs[item.ID] = int(id)
+			}
+		}
+
+		// 所有条目创建后再写入登录方式，关联的账号条目可能排在后面
+		for i, item := range items {
+			links, err := importProviders(tx, item, newIDs)
+			if err != nil {
+				return err
+			}
+			if err := setPasswordProviders(tx, created[i], links); err != nil {
+				return err
+			}
+		}
+
+		// 引用的条目同样可能排在后面，全部创建后再改写
+		for _, p := range saved {
+			changed, err := rewriteRefs(&p, newIDs)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				continue
+			}
+			if err := updatePassword(tx, p); err != nil {
+				return err
+			}
+			if err := setPasswordFields(tx, p.ID, p.Fields); err != nil {
+				return err
+			}
+			if err := setPasswordRefs(tx, p.ID, p.Refs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
+// importProviders 按名称解析导入条目的登录方式，身份提供方不存在时自动创建，
+// 关联的SSO账号不在导入文件中时取消关联。旧版本文件中的authLogins按legacy_key转换
+func importProviders(q querier, item models.ExportItem, newIDs map[int]int) ([]models.PasswordProvider, error) {
+	var links []models.PasswordProvider
+	for _, link := range item.Providers {
+		name := strings.TrimSpace(link.Name)
+		if name == "" {
+			continue
+		}
+		providerID, err := ensureIdentityProvider(q, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var accountID *int
+		if link.AccountID != nil {
+			if id, ok := newIDs[*link.AccountID]; ok {
+				accountID = &id
+			}
+		}
+		links = append(links, models.PasswordProvider{ProviderID: providerID, AccountID: accountID})
+	}
+
+	for key, enabled := range item.AuthLogins {
+		if !enabled {
+			continue
+		}
+		providerID, err := legacyProviderID(q, key)
+		if err != nil {
+			return nil, err
+		}
+		if providerID != 0 {
+			links = append(links, models.PasswordProvider{ProviderID: providerID})
+		}
+	}
+	return links, nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// 回收站条目的保留天数，0表示不自动清除
+const (
+	TrashRetentionSetting     = "trash_retention_days"
+	DefaultTrashRetentionDays = 30
+)
+
+// GetTrashedPasswords 获取回收站中的条目，最近删除的在前
+func GetTrashedPasswords() ([]models.Password, error) {
+	return queryPasswords("SELECT " + passwordColumns + " FROM passwords WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC")
+}
+
+// RestoreTrashedPassword 将条目从回收站恢复
+func RestoreTrashedPassword(id int) error {
+	result, err := DB.Exec("UPDATE passwords SET deleted_at = NULL, revision = revision + 1 WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// PurgeTrashedPassword 彻底删除回收站中的一个条目
+func PurgeTrashedPassword(id int) error {
+	n, err := purgeTrash("id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// EmptyTrash 彻底删除回收站中的所有条目，返回删除的数量
+func EmptyTrash() (int64, error) {
+	return purgeTrash("1 = 1")
+}
+
+// PurgeExpiredTrash 彻底删除在回收站中超过保留天数的条目，返回删除的数量
+func PurgeExpiredTrash() (int64, error) {
+	days := GetIntSetting(TrashRetentionSetting, DefaultTrashRetentionDays)
+	if days <= 0 {
+		return 0, nil
+	}
+	return purgeTrash("deleted_at < ?", time.Now().AddDate(0, 0, -days))
+}
+
+// purgeTrash 彻底删除回收站中满足条件的条目。
+// 删除前先覆盖条目及其历史版本、自定义字段的内容，连接开启了secure_delete，
+// 释放的页面会被清零；全文索引删除条目后旧词条仍留在索引段中，需合并索引段才会清除；
+// 最后执行WAL检查点，避免旧内容残留在日志文件中。附件的加密文件在事务提交后删除
+func purgeTrash(cond string, args ...interface{}) (int64, error) {
+	var purged int64
+	var blobIDs []string
+	err := withTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT id FROM passwords WHERE deleted_at IS NOT NULL AND "+cond, args...)
+		if err != nil {
+			return err
+		}
+		var ids []interface{}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ")"
+
+		// 附件文件在事务提交后删除
+		blobRows, err := tx.Query("SELECT blob_id FROM attachments WHERE password_id IN "+in, ids...)
+		if err != nil {
+			return err
+		}
+		for blobRows.Next() {
+			var blobID string
+			if err := blobRows.Scan(&blobID); err != nil {
+				blobRows.Close()
+				return err
+			}
+			blobIDs = append(blobIDs, blobID)
+		}
+		blobRows.Close()
+		if err := blobRows.Err(); err != nil {
+			return err
+		}
+
+		statements := []string{
+			"UPDATE password_history SET password = zeroblob(length(password)) WHERE password_id IN " + in,
+			"DELETE FROM password_history WHERE password_id IN " + in,
+			"UPDATE password_fields SET name = '', value = zeroblob(length(value)) WHERE password_id IN " + in,
+			"DELETE FROM password_fields WHERE password_id IN " + in,
+			"UPDATE password_uris SET uri = zeroblob(length(uri)) WHERE password_id IN " + in,
+			"DELETE FROM password_uris WHERE password_id IN " + in,
+			"UPDATE attachments SET file_name = '' WHERE password_id IN " + in,
+			"DELETE FROM attachments WHERE password_id IN " + in,
+			`UPDATE passwords SET name = '', username = '', phone = '', password = zeroblob(length(password)),
+				notes = '', item_data = zeroblob(length(item_data)), item_search = '' WHERE id IN ` + in,
+			"DELETE FROM passwords WHERE id IN " + in,
+		}
+		for _, stmt := range statements {
+			if _, err := tx.Exec(stmt, ids...); err != nil {
+				return err
+			}
+		}
+
+		purged = int64(len(ids))
+		return nil
+	})
+	if err != nil || purged == 0 {
+		return purged, err
+	}
+
+	for _, blobID := range blobIDs {
+		RemoveAttachmentFile(blobID)
+	}
+
+	if searchIndexReady {
+		if _, err := DB.Exec("INSERT INTO passwords_fts(passwords_fts) VALUES('optimize')"); err != nil {
+			log.Printf("清除回收站后合并全文索引失败: %v", err)
+		}
+	}
+
+	if _, err := DB.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		log.Printf("清除回收站后执行WAL检查点失败: %v", err)
+	}
+	return purged, nil
+}
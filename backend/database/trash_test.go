@@ -0,0 +1,36 @@
+package database
+
+import "testing"
+
+// 清除回收站后全文索引中不应残留已删除条目的词条
+func TestPurgeTrashOptimizesSearchIndex(t *testing.T) {
+	openTestDB(t)
+	if err := ensureSearchIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if !searchIndexReady {
+		t.Skip("SQLite未启用FTS5，需使用 -tags sqlite_fts5 运行")
+	}
+
+	const secret = "zqxjleakedname"
+	result, err := DB.Exec("INSERT INTO passwords (name, username, password) VALUES (?, 'alice', x'00')", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := result.LastInsertId()
+	if _, err := DB.Exec("UPDATE passwords SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", id); err != nil {
+		t.Fatal(err)
+	}
+	if err := PurgeTrashedPassword(int(id)); err != nil {
+		t.Fatal(err)
+	}
+
+	var leaked int
+	err = DB.QueryRow("SELECT COUNT(*) FROM passwords_fts_data WHERE instr(block, CAST(? AS BLOB)) > 0", secret).Scan(&leaked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaked != 0 {
+		t.Errorf("%d index blocks still contain the purged name", leaked)
+	}
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"github.com/007Secret/007Password/models"
+)
+
+// setPasswordURIs 替换条目的全部网址，按提交顺序保存
+func setPasswordURIs(q querier, passwordID int, uris []models.PasswordURI) error {
+	if _, err := q.Exec("DELETE FROM password_uris WHERE password_id = ?", passwordID); err != nil {
+		return err
+	}
+
+	for position, u := range uris {
+		_, err := q.Exec(
+			"INSERT INTO password_uris (password_id, uri, match_mode, position) VALUES (?, ?, ?, ?)",
+			passwordID, u.URI, u.Match, position,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachURIs 为条目填充网址，没有网址的条目得到空数组
+func attachURIs(q querier, passwords []models.Password) error {
+	if len(passwords) == 0 {
+		return nil
+	}
+
+	query := "SELECT id, password_id, uri, match_mode FROM password_uris"
+	where, args := passwordIDFilter("password_id", passwords)
+	query += where
+	query += " ORDER BY password_id, position, id"
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	urisByID := make(map[int][]models.PasswordURI)
+	for rows.Next() {
+		var u models.PasswordURI
+		var passwordID int
+		if err := rows.Scan(&u.ID, &passwordID, &u.URI, &u.Match); err != nil {
+			return err
+		}
+		urisByID[passwordID] = append(urisByID[passwordID], u)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range passwords {
+		if uris, ok := urisByID[passwords[i].ID]; ok {
+			passwords[i].URIs = uris
+		} else {
+			passwords[i].URIs = []models.PasswordURI{}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/models"
+)
+
+// 到期检查的执行间隔
+const expiryCheckInterval = 24 * time.Hour
+
+// checkExpiry 每天检查一次即将到期和已过期的条目并生成提醒。
+// 上次执行时间保存在数据库中，重启服务或重新登录不会重复检查
+func checkExpiry() {
+	if database.DB == nil {
+		return
+	}
+
+	if value, err := database.GetSetting(database.ExpiryCheckSetting); err == nil {
+		if last, err := time.Parse(time.RFC3339, value); err == nil && time.Since(last) < expiryCheckInterval {
+			return
+		}
+	}
+
+	added, err := generateExpiryNotifications()
+	if err != nil {
+		log.Printf("检查条目到期失败: %v", err)
+		return
+	}
+	if err := database.SetSetting(database.ExpiryCheckSetting, time.Now().Format(time.RFC3339)); err != nil {
+		log.Printf("保存到期检查时间失败: %v", err)
+	}
+
+	if len(added) > 0 {
+		log.Printf("生成了 %d 条到期提醒", len(added))
+		deliver(added)
+	}
+}
+
+// generateExpiryNotifications 为即将到期和已过期的条目生成提醒，返回新增的提醒
+func generateExpiryNotifications() ([]models.Notification, error) {
+	days := database.GetIntSetting(database.ExpiryReminderSetting, database.DefaultExpiryReminderDays)
+	entries, err := database.ExpiringPasswords(days)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]models.Notification, 0, len(entries))
+	for _, e := range entries {
+		id, due := e.ID, e.DueAt
+		notes = append(notes, models.Notification{
+			Kind:       notificationKind(e),
+			PasswordID: &id,
+			Title:      notificationTitle(e),
+			Message:    notificationMessage(e),
+			DueAt:      &due,
+		})
+	}
+	return database.AddNotifications(notes)
+}
+
+func notificationKind(e models.ExpiringEntry) string {
+	if e.Overdue {
+		return models.NotificationOverdue
+	}
+	return models.NotificationExpiring
+}
+
+func notificationTitle(e models.ExpiringEntry) string {
+	if e.Overdue {
+		return fmt.Sprintf("「%s」已过期", e.Name)
+	}
+	return fmt.Sprintf("「%s」即将过期", e.Name)
+}
+
+// notificationMessage 生成提醒正文，只包含条目名称和日期，不包含任何敏感内容
+func notificationMessage(e models.ExpiringEntry) string {
+	action := "过期"
+	if e.Reason == models.DueReasonRotation {
+		action = "需要更换密码"
+	}
+	date := e.DueAt.Local().Format("2006-01-02")
+	if e.Overdue {
+		return fmt.Sprintf("「%s」已于 %s %s，请尽快处理", e.Name, date, action)
+	}
+	return fmt.Sprintf("「%s」将于 %s %s（剩余 %d 天）", e.Name, date, action, e.DaysLeft)
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/007Secret/007Password/database"
+)
+
+// 后台任务的执行间隔，每日任务在此基础上按上次执行时间判断是否到期
+const purgeInterval = time.Hour
+
+// Start 启动后台定时任务。数据库在登录前处于锁定状态，此时任务直接跳过
+func Start() {
+	go func() {
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+
+		for {
+			purgeExpiredTrash()
+			checkExpiry()
+			scoreStrength()
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeExpiredTrash 彻底删除超过保留期限的回收站条目
+func purgeExpiredTrash() {
+	if database.DB == nil {
+		return
+	}
+
+	purged, err := database.PurgeExpiredTrash()
+	if err != nil {
+		log.Printf("清除过期回收站条目失败: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("已彻底删除 %d 个过期的回收站条目", purged)
+	}
+}
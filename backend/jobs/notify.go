@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/007Secret/007Password/models"
+)
+
+// 提醒的发送方式通过环境变量配置，未配置时提醒只保存在通知列表中：
+//
+//	NOTIFY_SMTP_ADDR      SMTP服务器地址，如 localhost:25
+//	NOTIFY_SMTP_FROM      发件人
+//	NOTIFY_SMTP_TO        收件人，多个用逗号分隔
+//	NOTIFY_SMTP_USERNAME  SMTP用户名，可选
+//	NOTIFY_SMTP_PASSWORD  SMTP密码，可选
+//	NOTIFY_WEBHOOK_URL    Webhook地址，提醒以JSON格式POST到该地址
+const webhookTimeout = 10 * time.Second
+
+// webhookPayload Webhook请求的内容
+type webhookPayload struct {
+	Notifications []models.Notification `json:"notifications"`
+}
+
+// deliver 将新生成的提醒发送到配置的SMTP服务器和Webhook，发送失败只记录日志
+func deliver(notes []models.Notification) {
+	if addr := os.Getenv("NOTIFY_SMTP_ADDR"); addr != "" {
+		if err := sendMail(addr, notes); err != nil {
+			log.Printf("发送提醒邮件失败: %v", err)
+		}
+	}
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		if err := postWebhook(url, notes); err != nil {
+			log.Printf("发送提醒Webhook失败: %v", err)
+		}
+	}
+}
+
+// sendMail 把所有提醒合并为一封邮件发送
+func sendMail(addr string, notes []models.Notification) error {
+	from := os.Getenv("NOTIFY_SMTP_FROM")
+	var to []string
+	for _, rcpt := range strings.Split(os.Getenv("NOTIFY_SMTP_TO"), ",") {
+		if rcpt = strings.TrimSpace(rcpt); rcpt != "" {
+			to = append(to, rcpt)
+		}
+	}
+	if from == "" || len(to) == 0 {
+		return fmt.Errorf("未配置NOTIFY_SMTP_FROM或NOTIFY_SMTP_TO")
+	}
+
+	var auth smtp.Auth
+	if username := os.Getenv("NOTIFY_SMTP_USERNAME"); username != "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", username, os.Getenv("NOTIFY_SMTP_PASSWORD"), host)
+	}
+
+	var body strings.Builder
+	for _, n := range notes {
+		body.WriteString(n.Message + "\r\n")
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	subject := fmt.Sprintf("007Password：%d 个条目需要处理", len(notes))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(body.String())
+
+	return smtp.SendMail(addr, auth, from, to, msg.Bytes())
+}
+
+// postWebhook 以JSON格式发送提醒，非2xx响应视为失败
+func postWebhook(url string, notes []models.Notification) error {
+	data, err := json.Marshal(webhookPayload{Notifications: notes})
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"log"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/utils"
+)
+
+// scoreStrength 为还没有强度分数的条目计算密码强度，使弱密码筛选覆盖升级前创建的条目。
+// 密码为字段引用的条目无法评估，每次都会跳过
+func scoreStrength() {
+	if database.DB == nil {
+		return
+	}
+
+	passwords, err := database.PasswordsWithoutStrength()
+	if err != nil {
+		log.Printf("读取待评估强度的条目失败: %v", err)
+		return
+	}
+
+	scored := 0
+	for id, encrypted := range passwords {
+		decrypted, err := utils.DecryptPassword(encrypted)
+		if err != nil {
+			log.Printf("解密条目密码失败 ID=%d: %v", id, err)
+			continue
+		}
+		score := utils.EntryStrengthScore(decrypted)
+		if score == nil {
+			continue
+		}
+		if err := database.SetPasswordStrength(id, score); err != nil {
+			log.Printf("更新条目密码强度失败 ID=%d: %v", id, err)
+			continue
+		}
+		scored++
+	}
+	if scored > 0 {
+		log.Printf("已计算 %d 个条目的密码强度", scored)
+	}
+}
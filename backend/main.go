@@ -1,6 +1,9 @@
 package main
 
 import (
+	"bufio"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -8,12 +11,21 @@ import (
 
 	"github.com/007Secret/007Password/controllers"
 	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/jobs"
 	"github.com/007Secret/007Password/middleware"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	migratePlan := flag.Bool("migrate-plan", false, "打印待执行的数据库迁移计划后退出（主密码从环境变量 MASTER_PASSWORD 或标准输入读取）")
+	flag.Parse()
+
+	if *migratePlan {
+		printMigrationPlan()
+		return
+	}
+
 	log.Println("启动007Password管理器服务...")
 
 	// 创建数据目录
@@ -40,6 +52,9 @@ func main() {
 	}
 	log.Printf("数据库初始化完成")
 
+	// 启动后台定时任务
+	jobs.Start()
+
 	// 创建Gin路由
 	r := gin.Default()
 
@@ -52,8 +67,9 @@ func main() {
 	// 配置CORS
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "Accept"}
+	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "Accept", "If-Match", middleware.StepUpHeader}
+	config.ExposeHeaders = []string{"Content-Disposition", "ETag", "X-Total-Count", "X-Next-Cursor", "Link"}
 	r.Use(cors.New(config))
 
 	// 添加请求日志记录中间件
@@ -84,8 +100,11 @@ func main() {
 		public.GET("/validate", middleware.AuthRequired(), controllers.ValidateToken)
 		public.POST("/setup", controllers.SetMasterPassword)
 		public.GET("/check-first-time", controllers.CheckFirstTimeSetup)
+		public.POST("/password-strength", controllers.CheckPasswordStrength)
 		// 修改主密码需要授权
 		public.POST("/change-password", middleware.AuthRequired(), controllers.ChangeMasterPassword)
+		// 查看受保护条目前重新验证主密码
+		public.POST("/step-up", middleware.AuthRequired(), controllers.StepUp)
 	}
 
 	// 需要授权的API
@@ -97,8 +116,84 @@ func main() {
 		authorized.GET("/passwords/:id", controllers.GetPasswordByID)
 		authorized.POST("/passwords", controllers.CreatePassword)
 		authorized.PUT("/passwords/:id", controllers.UpdatePassword)
+		authorized.POST("/passwords/batch", controllers.BatchPasswords)
+		authorized.PATCH("/passwords/:id", controllers.PatchPassword)
 		authorized.DELETE("/passwords/:id", controllers.DeletePassword)
 		authorized.GET("/passwords/search", controllers.SearchPasswords)
+		authorized.GET("/passwords/match", controllers.MatchPasswords)
+		authorized.GET("/passwords/expiring", controllers.GetExpiringPasswords)
+		authorized.POST("/passwords/:id/reveal", controllers.RevealPassword)
+		authorized.GET("/passwords/:id/history", controllers.GetPasswordHistory)
+		authorized.POST("/passwords/:id/history/:historyId/restore", controllers.RestorePasswordHistory)
+		authorized.GET("/passwords/:id/attachments", controllers.GetAttachments)
+		authorized.POST("/passwords/:id/attachments", controllers.UploadAttachment)
+		authorized.GET("/passwords/:id/attachments/:attachmentId", controllers.DownloadAttachment)
+		authorized.DELETE("/passwords/:id/attachments/:attachmentId", controllers.DeleteAttachment)
+		authorized.GET("/passwords/:id/totp", controllers.GetTOTPCode)
+		authorized.POST("/passwords/:id/totp", controllers.NextOTPCode)
+		authorized.PUT("/passwords/:id/favorite", controllers.SetFavorite)
+		authorized.POST("/passwords/:id/use", controllers.RecordUse)
+		authorized.GET("/passwords/:id/dependents", controllers.GetRefDependents)
+
+		// 解析TOTP密钥或二维码图片
+		authorized.POST("/totp/parse", controllers.ParseTOTP)
+
+		// 条目类型和导入导出API
+		authorized.GET("/item-types", controllers.GetItemTypes)
+		authorized.GET("/export", controllers.ExportPasswords)
+		authorized.POST("/import", controllers.ImportPasswords)
+
+		// 文件夹API
+		authorized.GET("/folders", controllers.GetFolders)
+		authorized.POST("/folders", controllers.CreateFolder)
+		authorized.PUT("/folders/:id", controllers.UpdateFolder)
+		authorized.DELETE("/folders/:id", controllers.DeleteFolder)
+
+		// 标签API
+		authorized.GET("/tags", controllers.GetTags)
+		authorized.POST("/tags", controllers.CreateTag)
+		authorized.PUT("/tags/:id", controllers.RenameTag)
+		authorized.DELETE("/tags/:id", controllers.DeleteTag)
+
+		// 智能文件夹API
+		authorized.GET("/smart-folders", controllers.GetSmartFolders)
+		authorized.POST("/smart-folders", controllers.CreateSmartFolder)
+		authorized.PUT("/smart-folders/:id", controllers.UpdateSmartFolder)
+		authorized.DELETE("/smart-folders/:id", controllers.DeleteSmartFolder)
+		authorized.GET("/smart-folders/:id/passwords", controllers.GetSmartFolderPasswords)
+
+		// 身份提供方API
+		authorized.GET("/identity-providers", controllers.GetIdentityProviders)
+		authorized.POST("/identity-providers", controllers.CreateIdentityProvider)
+		authorized.PUT("/identity-providers/:id", controllers.RenameIdentityProvider)
+		authorized.DELETE("/identity-providers/:id", controllers.DeleteIdentityProvider)
+		authorized.GET("/identity-providers/:id/exposure", controllers.GetProviderExposure)
+
+		// 回收站API
+		authorized.GET("/trash", controllers.GetTrash)
+		authorized.DELETE("/trash", controllers.EmptyTrash)
+		authorized.POST("/trash/:id/restore", controllers.RestoreTrash)
+		authorized.DELETE("/trash/:id", controllers.PurgeTrash)
+
+		// 配置项API
+		authorized.GET("/settings", controllers.GetSettings)
+		authorized.PUT("/settings", controllers.UpdateSettings)
+
+		// 条目模板API
+		authorized.GET("/templates", controllers.GetTemplates)
+		authorized.POST("/templates", controllers.CreateTemplate)
+		authorized.GET("/templates/:id", controllers.GetTemplate)
+		authorized.PUT("/templates/:id", controllers.UpdateTemplate)
+		authorized.DELETE("/templates/:id", controllers.DeleteTemplate)
+		authorized.POST("/templates/:id/entries", controllers.CreateEntryFromTemplate)
+
+		// 提醒API
+		authorized.GET("/notifications", controllers.GetNotifications)
+		authorized.PUT("/notifications/read-all", controllers.MarkAllNotificationsRead)
+		authorized.PUT("/notifications/:id/read", controllers.MarkNotificationRead)
+
+		// 审计日志API
+		authorized.GET("/audit", controllers.GetAuditLogs)
 	}
 
 	// 启动服务
@@ -111,3 +206,32 @@ func main() {
 		log.Fatalf("服务启动失败: %v", err)
 	}
 }
+
+// printMigrationPlan 打印当前数据库版本和待执行的迁移
+func printMigrationPlan() {
+	key := os.Getenv("MASTER_PASSWORD")
+	if key == "" {
+		fmt.Fprint(os.Stderr, "请输入主密码（未加密数据库直接回车）: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalf("读取主密码失败: %v", err)
+		}
+		key = strings.TrimRight(line, "\r\n")
+	}
+
+	current, pending, err := database.MigrationPlan(key)
+	if err != nil {
+		log.Fatalf("获取迁移计划失败: %v", err)
+	}
+
+	fmt.Printf("当前数据库版本: %d\n", current)
+	if len(pending) == 0 {
+		fmt.Println("没有待执行的迁移")
+		return
+	}
+
+	fmt.Printf("待执行的迁移 (%d):\n", len(pending))
+	for _, m := range pending {
+		fmt.Printf("  %04d_%s\n", m.Version, m.Name)
+	}
+}
@@ -23,8 +23,12 @@ var (
 	masterPasswordLock sync.RWMutex
 )
 
+// 登录令牌的作用域，二次验证令牌使用同一密钥签名，不能当作登录令牌使用
+const sessionScope = "session"
+
 // Claims JWT的声明结构
 type Claims struct {
+	Scope string `json:"scope"`
 	jwt.StandardClaims
 }
 
@@ -70,6 +74,7 @@ func GetMasterPassword() string {
 func GenerateToken() (string, error) {
 	// 创建声明
 	claims := Claims{
+		Scope: sessionScope,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(time.Hour * 24).Unix(), // 24小时后过期
 			IssuedAt:  time.Now().Unix(),
@@ -129,8 +134,8 @@ func AuthRequired() gin.HandlerFunc {
 			return
 		}
 
-		if !token.Valid {
-			log.Printf("Token is invalid")
+		if !token.Valid || claims.Scope != sessionScope {
+			log.Printf("Token is invalid, scope: %q", claims.Scope)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的令牌"})
 			c.Abort()
 			return
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// StepUpHeader 携带二次验证令牌的请求头
+	StepUpHeader = "X-Step-Up-Token"
+
+	// 二次验证令牌的有效期，过期后需要重新输入主密码
+	stepUpTokenTTL = 5 * time.Minute
+
+	// 二次验证令牌的作用域，防止与普通登录令牌混用
+	stepUpScope = "step_up"
+)
+
+// StepUpClaims 二次验证令牌的声明结构
+type StepUpClaims struct {
+	Scope string `json:"scope"`
+	jwt.StandardClaims
+}
+
+// GenerateStepUpToken 生成短时有效的二次验证令牌
+func GenerateStepUpToken() (string, time.Time, error) {
+	expiresAt := time.Now().Add(stepUpTokenTTL)
+	claims := StepUpClaims{
+		Scope: stepUpScope,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expiresAt.Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(secretKey))
+	if err != nil {
+		log.Printf("生成二次验证令牌失败: %v", err)
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// HasValidStepUp 检查请求是否携带有效的二次验证令牌
+func HasValidStepUp(c *gin.Context) bool {
+	tokenStr := c.GetHeader(StepUpHeader)
+	if tokenStr == "" {
+		return false
+	}
+
+	claims := &StepUpClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil || !token.Valid {
+		log.Printf("二次验证令牌无效: %v", err)
+		return false
+	}
+
+	return claims.Scope == stepUpScope
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Attachment 表示条目的一个附件，文件内容加密保存在附件目录中
+type Attachment struct {
+	ID          int       `json:"id"`
+	PasswordID  int       `json:"passwordId"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	BlobID      string    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// 审计日志动作类型
+const (
+	AuditActionReveal  = "reveal"
+	AuditActionView    = "view"
+	AuditActionRestore = "restore"
+	AuditActionExport  = "export"
+	AuditActionTOTP    = "totp"
+)
+
+// AuditLog 表示一条敏感数据访问记录
+type AuditLog struct {
+	ID         int       `json:"id"`
+	Action     string    `json:"action"`
+	PasswordID int       `json:"passwordId"`
+	Field      string    `json:"field"`
+	ClientIP   string    `json:"clientIp"`
+	UserAgent  string    `json:"userAgent"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
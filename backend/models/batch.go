@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 批量操作的执行模式
+const (
+	BatchModeAtomic     = "atomic"     // 任何一项失败都回滚全部操作
+	BatchModeBestEffort = "bestEffort" // 失败的操作单独回滚，其余照常提交
+)
+
+// BatchRequest 批量操作请求，操作按顺序在一个事务中执行
+type BatchRequest struct {
+	Mode       string           `json:"mode"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// AnyRevision 批量操作中不检查条目修订号，相当于 If-Match: *
+const AnyRevision = -1
+
+// BatchRevision 批量操作中客户端所见的条目修订号，JSON中为数字，"*" 或 -1 表示不检查（AnyRevision），
+// 未提供时为0
+type BatchRevision int
+
+// UnmarshalJSON 接受数字和 "*"
+func (r *BatchRevision) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s != "*" {
+			return fmt.Errorf("无效的修订号: %q", s)
+		}
+		*r = AnyRevision
+		return nil
+	}
+
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("无效的修订号: %s", data)
+	}
+	*r = BatchRevision(n)
+	return nil
+}
+
+// BatchOperation 批量操作中的一项。
+// Op为 create、update、delete、tag 或 move；除create外都必须提供Revision，
+// 只有条目当前修订号与之一致才执行，"*" 表示不检查
+type BatchOperation struct {
+	Op         string          `json:"op"`
+	ID         int             `json:"id"`
+	Revision   BatchRevision   `json:"revision"`
+	Data       json.RawMessage `json:"data"`       // create为完整条目，update为JSON Merge Patch
+	AddTags    []string        `json:"addTags"`    // tag添加的标签
+	RemoveTags []string        `json:"removeTags"` // tag移除的标签
+	FolderID   *int            `json:"folderId"`   // move的目标文件夹，null表示移出文件夹
+}
+
+// BatchResult 单项操作的结果，Status与单独调用对应接口时的HTTP状态码一致，
+// 424表示因其他操作失败而未执行
+type BatchResult struct {
+	Index    int    `json:"index"`
+	Op       string `json:"op"`
+	ID       int    `json:"id,omitempty"`
+	Status   int    `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Revision int    `json:"revision,omitempty"` // 操作后条目的修订号
+}
+
+// BatchResponse 批量操作的结果
+type BatchResponse struct {
+	Mode      string        `json:"mode"`
+	Committed bool          `json:"committed"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBatchRevisionUnmarshal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    BatchRevision
+		wantErr bool
+	}{
+		{`{"op":"delete","id":1}`, 0, false},
+		{`{"op":"delete","id":1,"revision":3}`, 3, false},
+		{`{"op":"delete","id":1,"revision":"*"}`, AnyRevision, false},
+		{`{"op":"delete","id":1,"revision":-1}`, AnyRevision, false},
+		{`{"op":"delete","id":1,"revision":"3"}`, 0, true},
+		{`{"op":"delete","id":1,"revision":1.5}`, 0, true},
+	}
+
+	for _, tt := range tests {
+		var op BatchOperation
+		err := json.Unmarshal([]byte(tt.input), &op)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && op.Revision != tt.want {
+			t.Errorf("Unmarshal(%s) revision = %d, want %d", tt.input, op.Revision, tt.want)
+		}
+	}
+}
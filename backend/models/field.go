@@ -0,0 +1,25 @@
+package models
+
+// 自定义字段类型
+const (
+	FieldTypeText   = "text"
+	FieldTypeHidden = "hidden"
+	FieldTypeURL    = "url"
+	FieldTypeEmail  = "email"
+	FieldTypeDate   = "date"
+	FieldTypeTOTP   = "totp"
+)
+
+// CustomField 表示条目上的一个自定义字段
+type CustomField struct {
+	ID     int    `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Masked bool   `json:"masked,omitempty"`
+}
+
+// IsSecret 判断字段值是否需要加密保存
+func (f CustomField) IsSecret() bool {
+	return f.Type == FieldTypeHidden || f.Type == FieldTypeTOTP
+}
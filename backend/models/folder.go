@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Folder 表示条目所在的文件夹，ParentID为空时位于根目录
+type Folder struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  *int      `json:"parentId"`
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Tag 表示条目的标签
+type Tag struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"createdAt"`
+}
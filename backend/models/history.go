@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// 密码历史记录的产生原因
+const (
+	HistoryReasonUpdate  = "update"
+	HistoryReasonRestore = "restore"
+)
+
+// PasswordHistory 表示条目密码被替换前的一个历史版本
+type PasswordHistory struct {
+	ID         int       `json:"id"`
+	PasswordID int       `json:"passwordId"`
+	Password   string    `json:"password"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import "strings"
+
+// 条目类型
+const (
+	ItemTypeLogin           = "login"
+	ItemTypeSecureNote      = "secure_note"
+	ItemTypeCard            = "card"
+	ItemTypeIdentity        = "identity"
+	ItemTypeBankAccount     = "bank_account"
+	ItemTypeSoftwareLicense = "software_license"
+	ItemTypeWifi            = "wifi"
+)
+
+// 类型字段的取值格式，用于校验
+const (
+	ItemFormatText       = "text"
+	ItemFormatEmail      = "email"
+	ItemFormatDate       = "date"        // YYYY-MM-DD
+	ItemFormatExpiry     = "expiry"      // MM/YYYY
+	ItemFormatCardNumber = "card_number" // 通过Luhn校验
+	ItemFormatCVV        = "cvv"         // 3-4位数字
+	ItemFormatIBAN       = "iban"        // 通过MOD-97校验
+	ItemFormatBool       = "bool"        // true 或 false
+	ItemFormatWifiSecure = "wifi_security"
+)
+
+// ItemField 描述条目类型中的一个字段
+type ItemField struct {
+	Key      string `json:"key"`
+	Format   string `json:"format"`
+	Required bool   `json:"required,omitempty"`
+	// Secret 为true的字段在列表中隐藏，需通过reveal接口获取
+	Secret bool `json:"secret,omitempty"`
+}
+
+// ItemSchemas 各条目类型的字段定义。登录类型使用passwords表原有的列，没有额外字段
+var ItemSchemas = map[string][]ItemField{
+	ItemTypeLogin: {},
+	ItemTypeSecureNote: {
+		{Key: "content", Format: ItemFormatText, Required: true, Secret: true},
+	},
+	ItemTypeCard: {
+		{Key: "cardholderName", Format: ItemFormatText},
+		{Key: "brand", Format: ItemFormatText},
+		{Key: "number", Format: ItemFormatCardNumber, Required: true, Secret: true},
+		{Key: "expiry", Format: ItemFormatExpiry, Required: true},
+		{Key: "cvv", Format: ItemFormatCVV, Secret: true},
+		{Key: "pin", Format: ItemFormatText, Secret: true},
+	},
+	ItemTypeIdentity: {
+		{Key: "firstName", Format: ItemFormatText},
+		{Key: "lastName", Format: ItemFormatText},
+		{Key: "email", Format: ItemFormatEmail},
+		{Key: "phone", Format: ItemFormatText},
+		{Key: "birthday", Format: ItemFormatDate},
+		{Key: "address", Format: ItemFormatText},
+		{Key: "city", Format: ItemFormatText},
+		{Key: "postalCode", Format: ItemFormatText},
+		{Key: "country", Format: ItemFormatText},
+		{Key: "idNumber", Format: ItemFormatText, Secret: true},
+		{Key: "passportNumber", Format: ItemFormatText, Secret: true},
+	},
+	ItemTypeBankAccount: {
+		{Key: "bankName", Format: ItemFormatText, Required: true},
+		{Key: "accountHolder", Format: ItemFormatText},
+		{Key: "accountNumber", Format: ItemFormatText, Secret: true},
+		{Key: "iban", Format: ItemFormatIBAN, Secret: true},
+		{Key: "swift", Format: ItemFormatText},
+		{Key: "routingNumber", Format: ItemFormatText},
+		{Key: "pin", Format: ItemFormatText, Secret: true},
+	},
+	ItemTypeSoftwareLicense: {
+		{Key: "product", Format: ItemFormatText, Required: true},
+		{Key: "version", Format: ItemFormatText},
+		{Key: "licenseKey", Format: ItemFormatText, Required: true, Secret: true},
+		{Key: "licensedTo", Format: ItemFormatText},
+		{Key: "email", Format: ItemFormatEmail},
+		{Key: "purchaseDate", Format: ItemFormatDate},
+		{Key: "expiresOn", Format: ItemFormatDate},
+	},
+	ItemTypeWifi: {
+		{Key: "ssid", Format: ItemFormatText, Required: true},
+		{Key: "password", Format: ItemFormatText, Secret: true},
+		{Key: "security", Format: ItemFormatWifiSecure},
+		{Key: "hidden", Format: ItemFormatBool},
+	},
+}
+
+// ItemSearchText 将非敏感的类型字段按定义的顺序以空格拼接，保存为明文用于搜索。敏感字段只保存在加密的内容中
+func ItemSearchText(itemType string, data map[string]string) string {
+	var values []string
+	for _, f := range ItemSchemas[itemType] {
+		if v := data[f.Key]; v != "" && !f.Secret {
+			values = append(values, v)
+		}
+	}
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,23 @@
+package models
+
+import "testing"
+
+func TestItemSearchText(t *testing.T) {
+	tests := []struct {
+		itemType string
+		data     map[string]string
+		want     string
+	}{
+		{ItemTypeBankAccount, map[string]string{"bankName": "招商银行", "accountNumber": "6225", "swift": "CMBCCNBS"}, "招商银行 CMBCCNBS"},
+		{ItemTypeWifi, map[string]string{"ssid": "home", "password": "secret", "security": "wpa2"}, "home wpa2"},
+		{ItemTypeCard, map[string]string{"cardholderName": "Alice", "number": "4111111111111111", "cvv": "123"}, "Alice"},
+		{ItemTypeSecureNote, map[string]string{"content": "secret"}, ""},
+		{ItemTypeLogin, nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := ItemSearchText(tt.itemType, tt.data); got != tt.want {
+			t.Errorf("ItemSearchText(%s, %v) = %q, want %q", tt.itemType, tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// 提醒类型
+const (
+	NotificationExpiring = "expiring" // 即将到期
+	NotificationOverdue  = "overdue"  // 已过期
+)
+
+// 条目到期的原因
+const (
+	DueReasonExpires  = "expires"  // 到达设置的过期时间
+	DueReasonRotation = "rotation" // 到达定期更换密码的时间
+)
+
+// Notification 表示一条后台任务生成的提醒
+type Notification struct {
+	ID         int        `json:"id"`
+	Kind       string     `json:"kind"`
+	PasswordID *int       `json:"passwordId"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	DueAt      *time.Time `json:"dueAt"`
+	ReadAt     *time.Time `json:"readAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// ExpiringEntry 表示即将到期或已过期的条目
+type ExpiringEntry struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	ItemType string    `json:"itemType"`
+	DueAt    time.Time `json:"dueAt"`
+	Reason   string    `json:"reason"`
+	Overdue  bool      `json:"overdue"`
+	DaysLeft int       `json:"daysLeft"` // 距到期的整天数，已过期时为负数，不足一天按0计
+}
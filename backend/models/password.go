@@ -2,33 +2,39 @@ package models
 
 import "time"
 
-// AuthLogins 表示授权登录服务
-type AuthLogins struct {
-	Google   bool `json:"google"`
-	Wechat   bool `json:"wechat"`
-	Weibo    bool `json:"weibo"`
-	Baidu    bool `json:"baidu"`
-	Facebook bool `json:"facebook"`
-	Github   bool `json:"github"`
-	QQ       bool `json:"qq"`
-	Alipay   bool `json:"alipay"`
-	Taobao   bool `json:"taobao"`
-	Dingtalk bool `json:"dingtalk"`
-	Douyin   bool `json:"douyin"`
-	Feishu   bool `json:"feishu"`
-	Twitter  bool `json:"twitter"`
-}
+// WeakPasswordScore 强度分数低于该值的密码视为弱密码
+const WeakPasswordScore = 3
 
 // Password 表示密码实体
 type Password struct {
-	ID         int        `json:"id"`
-	Name       string     `json:"name"`
-	Username   string     `json:"username"`
-	Phone      string     `json:"phone"`
-	Password   string     `json:"password"`
-	Website    string     `json:"website"`
-	AuthLogins AuthLogins `json:"authLogins"`
-	Notes      string     `json:"notes"`
-	CreatedAt  time.Time  `json:"createdAt"`
-	UpdatedAt  time.Time  `json:"updatedAt"`
+	ID         int                `json:"id"`
+	ItemType   string             `json:"itemType"`
+	Name       string             `json:"name"`
+	Username   string             `json:"username"`
+	Phone      string             `json:"phone"`
+	Password   string             `json:"password"`
+	URIs       []PasswordURI      `json:"uris"`
+	Providers  []PasswordProvider `json:"providers"`
+	Notes      string             `json:"notes"`
+	ItemData   map[string]string  `json:"itemData,omitempty"` // 非登录类型的字段，键由ItemSchemas定义
+	ItemCipher string             `json:"-"`                  // ItemData序列化后加密的内容，即数据库中保存的值
+	ItemSearch string             `json:"-"`                  // 非敏感类型字段的明文，以空格分隔，用于搜索
+	Protected  bool               `json:"protected"`
+	Favorite   bool               `json:"favorite"`
+	FolderID   *int               `json:"folderId"`
+	Tags       []string           `json:"tags"`
+	Fields     []CustomField      `json:"fields"`
+	Masked     bool               `json:"masked,omitempty"`
+	LastUsedAt *time.Time         `json:"lastUsedAt"`
+	UseCount   int                `json:"useCount"`
+	ExpiresAt  *time.Time         `json:"expiresAt"`
+	// RotationDays 定期更换密码的周期（天），0表示不需要定期更换
+	RotationDays      int        `json:"rotationDays"`
+	PasswordChangedAt *time.Time `json:"passwordChangedAt"` // 只读，修改密码时由服务端更新
+	Revision          int        `json:"revision"`          // 只读，每次修改条目内容时加一，即ETag的值
+	Strength          *int       `json:"strength"`          // 只读，密码强度分数（0-4），没有密码或无法评估时为空
+	Refs              []FieldRef `json:"-"`                 // 字段中的引用，保存时由服务端解析
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+	DeletedAt         *time.Time `json:"deletedAt,omitempty"`
 }
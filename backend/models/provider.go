@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// IdentityProvider 表示一个身份提供方（SSO登录方式），如 Google、微信或企业SSO
+type IdentityProvider struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PasswordProvider 表示条目可以通过某个身份提供方登录。
+// AccountID指向保存该SSO账号的条目，为空表示未关联
+type PasswordProvider struct {
+	ProviderID int    `json:"providerId"`
+	Name       string `json:"name,omitempty"`
+	AccountID  *int   `json:"accountId"`
+}
+
+// ExposedEntry 表示身份提供方被盗用时会受影响的条目
+type ExposedEntry struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	ItemType     string `json:"itemType"`
+	Depth        int    `json:"depth"` // 1为直接使用该身份提供方登录，更大的值为经由已暴露的SSO账号间接受影响
+	ProviderID   int    `json:"providerId"`
+	ProviderName string `json:"providerName"`
+	AccountID    *int   `json:"accountId,omitempty"` // 登录该条目所用的SSO账号条目，间接受影响时为已暴露的条目
+	// NoPasswordFallback 条目没有保存密码，只能通过SSO登录，无法绕开被盗用的账号
+	NoPasswordFallback bool `json:"noPasswordFallback"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// 引用中可以指定的目标字段，对应KeePass引用语法中的 T、U、P、A、N
+const (
+	RefFieldTitle    = "title"
+	RefFieldUsername = "username"
+	RefFieldPassword = "password"
+	RefFieldURL      = "url"
+	RefFieldNotes    = "notes"
+)
+
+// RefCustomFieldPrefix 引用位于自定义字段时，Field为该前缀加字段名称
+const RefCustomFieldPrefix = "field:"
+
+// FieldRef 表示条目字段中对另一个条目字段的引用
+type FieldRef struct {
+	Field       string `json:"field"` // 引用所在的字段，如 password 或 field:域账号
+	TargetID    int    `json:"targetId"`
+	TargetField string `json:"targetField"`
+}
+
+// RefDependent 表示引用了某个条目的条目
+type RefDependent struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	ItemType    string `json:"itemType"`
+	Field       string `json:"field"`
+	TargetField string `json:"targetField"`
+}
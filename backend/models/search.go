@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// SearchResult 搜索的结果，按得分从高到低排列
+type SearchResult struct {
+	Password
+	// Snippet 匹配内容的摘要，匹配的词用 <mark></mark> 标记，内容未做HTML转义
+	Snippet string `json:"snippet"`
+	// Score 相关度结合使用次数的得分，越大越靠前：精确匹配不低于1，模糊匹配低于1，
+	// 没有关键词只按条件筛选时为0
+	Score float64 `json:"score"`
+}
+
+// SmartFolder 智能文件夹，保存一条结构化搜索语句，打开时按语句实时查询条目
+type SmartFolder struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// 模板字段写入条目的位置
+const (
+	TemplateTargetField    = "field"    // 自定义字段
+	TemplateTargetUsername = "username" // 条目的用户名
+	TemplateTargetPassword = "password" // 条目的密码
+	TemplateTargetURI      = "uri"      // 条目的网址
+)
+
+// 生成规则的类型
+const (
+	GeneratorPassword   = "password"    // 随机密码
+	GeneratorPIN        = "pin"         // 纯数字
+	GeneratorSSHEd25519 = "ssh-ed25519" // OpenSSH格式的Ed25519私钥
+)
+
+// GeneratorRule 描述如何为模板字段生成值
+type GeneratorRule struct {
+	Kind   string `json:"kind"`
+	Length int    `json:"length,omitempty"` // 仅password和pin
+	// 以下仅password使用，全部为false时使用所有字符集
+	Lower   bool `json:"lower,omitempty"`
+	Upper   bool `json:"upper,omitempty"`
+	Digits  bool `json:"digits,omitempty"`
+	Symbols bool `json:"symbols,omitempty"`
+}
+
+// TemplateField 表示模板中的一个字段定义
+type TemplateField struct {
+	Name      string         `json:"name"`
+	Type      string         `json:"type"`   // 自定义字段类型，target为field时有效
+	Target    string         `json:"target"` // 为空时按field处理
+	Required  bool           `json:"required"`
+	Default   string         `json:"default,omitempty"`
+	Generator *GeneratorRule `json:"generator,omitempty"`
+}
+
+// Template 表示一个条目模板
+type Template struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	ItemType    string          `json:"itemType"`
+	Fields      []TemplateField `json:"fields"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// TemplateEntryRequest 从模板创建条目的请求数据，Values以模板字段名称为键
+type TemplateEntryRequest struct {
+	Name     string            `json:"name"`
+	Values   map[string]string `json:"values"`
+	ItemData map[string]string `json:"itemData,omitempty"`
+	FolderID *int              `json:"folderId"`
+	Tags     []string          `json:"tags"`
+	Notes    string            `json:"notes"`
+}
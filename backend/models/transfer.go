@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// 导出文件格式版本
+const ExportVersion = 3
+
+// ExportItem 导出文件中的一个条目，敏感内容均为明文，文件夹以路径表示
+type ExportItem struct {
+	Password
+	FolderPath string          `json:"folderPath,omitempty"`
+	Website    string          `json:"website,omitempty"`    // 版本1中条目的网址，版本2起改为uris
+	AuthLogins map[string]bool `json:"authLogins,omitempty"` // 版本2及以前的授权登录，版本3起改为providers
+}
+
+// ExportFile 导出文件，导入时使用同样的格式
+type ExportFile struct {
+	Version    int          `json:"version"`
+	ExportedAt time.Time    `json:"exportedAt"`
+	Items      []ExportItem `json:"items"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// 网址匹配规则
+const (
+	URIMatchDomain     = "domain"      // 基础域名相同，如 app.example.com 与 admin.example.com
+	URIMatchHost       = "host"        // 主机名和端口完全相同
+	URIMatchStartsWith = "starts_with" // 目标网址以该网址开头
+	URIMatchRegex      = "regex"       // 目标网址匹配该正则表达式
+	URIMatchNever      = "never"       // 从不参与自动匹配
+)
+
+// PasswordURI 表示条目的一个网址及其匹配规则
+type PasswordURI struct {
+	ID    int    `json:"id,omitempty"`
+	URI   string `json:"uri"`
+	Match string `json:"match"`
+}
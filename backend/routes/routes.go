@@ -15,7 +15,10 @@ func SetupRoutes(r *gin.Engine) {
 		authGroup.GET("/validate", middleware.AuthRequired(), controllers.ValidateToken)
 		authGroup.POST("/setup", controllers.SetMasterPassword)
 		authGroup.GET("/check-first-time", controllers.CheckFirstTimeSetup)
+		authGroup.POST("/password-strength", controllers.CheckPasswordStrength)
 		authGroup.POST("/change-password", middleware.AuthRequired(), controllers.ChangeMasterPassword)
+		// 查看受保护条目前重新验证主密码
+		authGroup.POST("/step-up", middleware.AuthRequired(), controllers.StepUp)
 	}
 
 	// 密码管理API
@@ -25,7 +28,107 @@ func SetupRoutes(r *gin.Engine) {
 		passwordGroup.GET("/:id", controllers.GetPasswordByID)
 		passwordGroup.POST("", controllers.CreatePassword)
 		passwordGroup.PUT("/:id", controllers.UpdatePassword)
+		passwordGroup.POST("/batch", controllers.BatchPasswords)
+		passwordGroup.PATCH("/:id", controllers.PatchPassword)
 		passwordGroup.DELETE("/:id", controllers.DeletePassword)
 		passwordGroup.GET("/search", controllers.SearchPasswords)
+		passwordGroup.GET("/match", controllers.MatchPasswords)
+		passwordGroup.GET("/expiring", controllers.GetExpiringPasswords)
+		passwordGroup.POST("/:id/reveal", controllers.RevealPassword)
+		passwordGroup.GET("/:id/history", controllers.GetPasswordHistory)
+		passwordGroup.POST("/:id/history/:historyId/restore", controllers.RestorePasswordHistory)
+		passwordGroup.GET("/:id/attachments", controllers.GetAttachments)
+		passwordGroup.POST("/:id/attachments", controllers.UploadAttachment)
+		passwordGroup.GET("/:id/attachments/:attachmentId", controllers.DownloadAttachment)
+		passwordGroup.DELETE("/:id/attachments/:attachmentId", controllers.DeleteAttachment)
+		passwordGroup.GET("/:id/totp", controllers.GetTOTPCode)
+		passwordGroup.POST("/:id/totp", controllers.NextOTPCode)
+		passwordGroup.PUT("/:id/favorite", controllers.SetFavorite)
+		passwordGroup.POST("/:id/use", controllers.RecordUse)
+		passwordGroup.GET("/:id/dependents", controllers.GetRefDependents)
 	}
+
+	// 解析TOTP密钥或二维码图片
+	r.POST("/api/totp/parse", middleware.AuthRequired(), controllers.ParseTOTP)
+
+	// 条目类型和导入导出API
+	r.GET("/api/item-types", middleware.AuthRequired(), controllers.GetItemTypes)
+	r.GET("/api/export", middleware.AuthRequired(), controllers.ExportPasswords)
+	r.POST("/api/import", middleware.AuthRequired(), controllers.ImportPasswords)
+
+	// 文件夹API
+	folderGroup := r.Group("/api/folders", middleware.AuthRequired())
+	{
+		folderGroup.GET("", controllers.GetFolders)
+		folderGroup.POST("", controllers.CreateFolder)
+		folderGroup.PUT("/:id", controllers.UpdateFolder)
+		folderGroup.DELETE("/:id", controllers.DeleteFolder)
+	}
+
+	// 标签API
+	tagGroup := r.Group("/api/tags", middleware.AuthRequired())
+	{
+		tagGroup.GET("", controllers.GetTags)
+		tagGroup.POST("", controllers.CreateTag)
+		tagGroup.PUT("/:id", controllers.RenameTag)
+		tagGroup.DELETE("/:id", controllers.DeleteTag)
+	}
+
+	// 智能文件夹API
+	smartFolderGroup := r.Group("/api/smart-folders", middleware.AuthRequired())
+	{
+		smartFolderGroup.GET("", controllers.GetSmartFolders)
+		smartFolderGroup.POST("", controllers.CreateSmartFolder)
+		smartFolderGroup.PUT("/:id", controllers.UpdateSmartFolder)
+		smartFolderGroup.DELETE("/:id", controllers.DeleteSmartFolder)
+		smartFolderGroup.GET("/:id/passwords", controllers.GetSmartFolderPasswords)
+	}
+
+	// 身份提供方API
+	providerGroup := r.Group("/api/identity-providers", middleware.AuthRequired())
+	{
+		providerGroup.GET("", controllers.GetIdentityProviders)
+		providerGroup.POST("", controllers.CreateIdentityProvider)
+		providerGroup.PUT("/:id", controllers.RenameIdentityProvider)
+		providerGroup.DELETE("/:id", controllers.DeleteIdentityProvider)
+		providerGroup.GET("/:id/exposure", controllers.GetProviderExposure)
+	}
+
+	// 回收站API
+	trashGroup := r.Group("/api/trash", middleware.AuthRequired())
+	{
+		trashGroup.GET("", controllers.GetTrash)
+		trashGroup.DELETE("", controllers.EmptyTrash)
+		trashGroup.POST("/:id/restore", controllers.RestoreTrash)
+		trashGroup.DELETE("/:id", controllers.PurgeTrash)
+	}
+
+	// 配置项API
+	settingsGroup := r.Group("/api/settings", middleware.AuthRequired())
+	{
+		settingsGroup.GET("", controllers.GetSettings)
+		settingsGroup.PUT("", controllers.UpdateSettings)
+	}
+
+	// 条目模板API
+	templateGroup := r.Group("/api/templates", middleware.AuthRequired())
+	{
+		templateGroup.GET("", controllers.GetTemplates)
+		templateGroup.POST("", controllers.CreateTemplate)
+		templateGroup.GET("/:id", controllers.GetTemplate)
+		templateGroup.PUT("/:id", controllers.UpdateTemplate)
+		templateGroup.DELETE("/:id", controllers.DeleteTemplate)
+		templateGroup.POST("/:id/entries", controllers.CreateEntryFromTemplate)
+	}
+
+	// 提醒API
+	notificationGroup := r.Group("/api/notifications", middleware.AuthRequired())
+	{
+		notificationGroup.GET("", controllers.GetNotifications)
+		notificationGroup.PUT("/read-all", controllers.MarkAllNotificationsRead)
+		notificationGroup.PUT("/:id/read", controllers.MarkNotificationRead)
+	}
+
+	// 审计日志API
+	r.GET("/api/audit", middleware.AuthRequired(), controllers.GetAuditLogs)
 }
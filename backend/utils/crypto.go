@@ -5,6 +5,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
@@ -54,23 +55,11 @@ func EncryptPassword(password string) (string, error) {
 		}
 	}
 
-	// 获取存储的盐值
-	salt, err := database.GetSetting("password_salt")
+	// 获取存储的盐值，不存在时创建
+	salt, err := passwordSalt()
 	if err != nil {
-		// 如果盐值不存在，则创建一个新的
-		if err.Error() == "sql: no rows in result set" {
-			log.Printf("未找到密码盐值，创建新的盐值")
-			salt = generateSalt()
-			err = database.SetSetting("password_salt", salt)
-			if err != nil {
-				log.Printf("创建盐值失败: %v", err)
-				return "", err
-			}
-			log.Printf("成功创建新的盐值，长度: %d", len(salt))
-		} else {
-			log.Printf("加密失败: 获取盐值出错: %v", err)
-			return "", err
-		}
+		log.Printf("加密失败: %v", err)
+		return "", err
 	}
 
 	log.Printf("加密密码: 找到盐值，长度: %d", len(salt))
@@ -208,6 +197,26 @@ func DecryptPassword(encryptedPassword string) (string, error) {
 	return string(plaintext), nil
 }
 
+// passwordSalt 获取派生密钥使用的盐值，只有盐值不存在时才创建新的盐值。
+// 数据库被锁定等其他读取错误直接返回，覆盖已有的盐值会导致已加密的内容全部无法解密
+func passwordSalt() (string, error) {
+	salt, err := database.GetSetting("password_salt")
+	if err == nil {
+		return salt, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("获取盐值失败: %w", err)
+	}
+
+	log.Printf("未找到密码盐值，创建新的盐值")
+	salt = generateSalt()
+	if err := database.SetSetting("password_salt", salt); err != nil {
+		return "", fmt.Errorf("创建盐值失败: %w", err)
+	}
+	log.Printf("成功创建新的盐值，长度: %d", len(salt))
+	return salt, nil
+}
+
 // deriveKey 根据主密码和盐值生成加密密钥
 func deriveKey(masterPassword, salt string) []byte {
 	// 简单的密钥派生，实际应用中可以使用PBKDF2或Argon2
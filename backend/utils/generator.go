@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/007Secret/007Password/models"
+	"golang.org/x/crypto/ssh"
+)
+
+// 生成规则的长度范围和默认值
+const (
+	defaultPasswordLength = 20
+	defaultPINLength      = 6
+	minGeneratedLength    = 4
+	maxGeneratedLength    = 128
+)
+
+// 随机密码使用的字符集
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!@#$%^&*()-_=+[]{};:,.?"
+)
+
+// ValidateGeneratorRule 校验生成规则
+func ValidateGeneratorRule(rule models.GeneratorRule) error {
+	switch rule.Kind {
+	case models.GeneratorPassword, models.GeneratorPIN:
+		if rule.Length != 0 && (rule.Length < minGeneratedLength || rule.Length > maxGeneratedLength) {
+			return fmt.Errorf("生成长度必须在%d到%d之间", minGeneratedLength, maxGeneratedLength)
+		}
+		if rule.Kind == models.GeneratorPassword {
+			sets := 0
+			for _, on := range []bool{rule.Lower, rule.Upper, rule.Digits, rule.Symbols} {
+				if on {
+					sets++
+				}
+			}
+			if rule.Length != 0 && sets > rule.Length {
+				return errors.New("生成长度不能小于选择的字符集数量")
+			}
+		}
+		return nil
+	case models.GeneratorSSHEd25519:
+		return nil
+	}
+	return fmt.Errorf("不支持的生成规则: %s", rule.Kind)
+}
+
+// Generate 按生成规则生成一个值，所有随机数来自crypto/rand
+func Generate(rule models.GeneratorRule) (string, error) {
+	if err := ValidateGeneratorRule(rule); err != nil {
+		return "", err
+	}
+
+	switch rule.Kind {
+	case models.GeneratorPIN:
+		length := rule.Length
+		if length == 0 {
+			length = defaultPINLength
+		}
+		return randomString([]string{digitChars}, length)
+	case models.GeneratorSSHEd25519:
+		return generateSSHKey()
+	}
+
+	length := rule.Length
+	if length == 0 {
+		length = defaultPasswordLength
+	}
+	var sets []string
+	if rule.Lower {
+		sets = append(sets, lowerChars)
+	}
+	if rule.Upper {
+		sets = append(sets, upperChars)
+	}
+	if rule.Digits {
+		sets = append(sets, digitChars)
+	}
+	if rule.Symbols {
+		sets = append(sets, symbolChars)
+	}
+	if len(sets) == 0 {
+		sets = []string{lowerChars, upperChars, digitChars, symbolChars}
+	}
+	return randomString(sets, length)
+}
+
+// randomString 生成指定长度的随机字符串，每个字符集至少出现一次
+func randomString(sets []string, length int) (string, error) {
+	var all string
+	for _, s := range sets {
+		all += s
+	}
+
+	out := make([]byte, length)
+	for i := range out {
+		// 前几位依次从每个字符集中选取，保证每个字符集都出现
+		chars := all
+		if i < len(sets) {
+			chars = sets[i]
+		}
+		c, err := randomChar(chars)
+		if err != nil {
+			return "", err
+		}
+		out[i] = c
+	}
+
+	// 打乱顺序，避免字符集出现的位置固定
+	for i := len(out) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		j := int(n.Int64())
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out), nil
+}
+
+func randomChar(chars string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+	if err != nil {
+		return 0, err
+	}
+	return chars[n.Int64()], nil
+}
+
+// generateSSHKey 生成OpenSSH格式的Ed25519私钥
+func generateSSHKey() (string, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
@@ -0,0 +1,23 @@
+package utils
+
+// MergePatch 按 JSON Merge Patch（RFC 7396）将patch合并到target：
+// 对象逐个键合并，值为null的键被删除，其他值（包括数组）整体替换
+func MergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{}, len(patchObj))
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = MergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
@@ -0,0 +1,306 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// 一次性密码类型
+const (
+	OTPTypeTOTP = "totp"
+	OTPTypeHOTP = "hotp"
+)
+
+// Steam令牌使用5位字符，字母表中去掉了易混淆的字符
+const (
+	steamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+	steamDigits   = 5
+)
+
+// OTP参数的默认值，与Google Authenticator一致
+const (
+	defaultOTPAlgorithm = "SHA1"
+	defaultOTPDigits    = 6
+	defaultOTPPeriod    = 30
+)
+
+// ErrInvalidOTP 无法解析的一次性密码密钥
+var ErrInvalidOTP = errors.New("无效的一次性密码密钥")
+
+// OTPKey 表示一个TOTP/HOTP密钥及其参数
+type OTPKey struct {
+	Type      string
+	Issuer    string
+	Account   string
+	Secret    string // 去掉空格和填充后的大写Base32
+	Algorithm string // SHA1、SHA256或SHA512
+	Digits    int
+	Period    int    // 仅TOTP，单位秒
+	Counter   uint64 // 仅HOTP，下一次生成使用的计数器
+	Steam     bool
+}
+
+// ParseOTP 解析一次性密码密钥，支持以下格式：
+//
+//	otpauth://totp/Issuer:account?secret=...&algorithm=SHA256&digits=8&period=60
+//	otpauth://hotp/Issuer:account?secret=...&counter=0
+//	otpauth://steam/... 或带 encoder=steam 参数的otpauth链接，以及 steam://SECRET
+//	直接填写的Base32密钥，按默认参数的TOTP处理
+func ParseOTP(value string) (*OTPKey, error) {
+	value = strings.TrimSpace(value)
+	key := &OTPKey{
+		Type:      OTPTypeTOTP,
+		Algorithm: defaultOTPAlgorithm,
+		Digits:    defaultOTPDigits,
+		Period:    defaultOTPPeriod,
+	}
+
+	lower := strings.ToLower(value)
+	switch {
+	case strings.HasPrefix(lower, "otpauth://"):
+		if err := key.parseURI(value); err != nil {
+			return nil, err
+		}
+	case strings.HasPrefix(lower, "steam://"):
+		key.Secret = value[len("steam://"):]
+		key.Steam = true
+	default:
+		key.Secret = value
+	}
+
+	secret, err := normalizeOTPSecret(key.Secret)
+	if err != nil {
+		return nil, err
+	}
+	key.Secret = secret
+
+	if key.Steam {
+		key.Type = OTPTypeTOTP
+		key.Algorithm = defaultOTPAlgorithm
+		key.Digits = steamDigits
+		key.Period = defaultOTPPeriod
+	}
+	return key, nil
+}
+
+// parseURI 解析otpauth链接中的类型、标签和参数
+func (k *OTPKey) parseURI(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ErrInvalidOTP
+	}
+
+	switch strings.ToLower(u.Host) {
+	case OTPTypeTOTP:
+		k.Type = OTPTypeTOTP
+	case OTPTypeHOTP:
+		k.Type = OTPTypeHOTP
+	case "steam":
+		k.Steam = true
+	default:
+		return fmt.Errorf("不支持的一次性密码类型: %s", u.Host)
+	}
+
+	// 标签格式为 Issuer:account，两部分都可省略
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		k.Issuer = strings.TrimSpace(issuer)
+		k.Account = strings.TrimSpace(account)
+	} else {
+		k.Account = strings.TrimSpace(label)
+	}
+
+	q := u.Query()
+	k.Secret = q.Get("secret")
+	if issuer := q.Get("issuer"); issuer != "" {
+		k.Issuer = issuer
+	}
+	if strings.EqualFold(q.Get("encoder"), "steam") {
+		k.Steam = true
+	}
+
+	if v := q.Get("algorithm"); v != "" {
+		k.Algorithm = strings.ToUpper(v)
+		if otpHash(k.Algorithm) == nil {
+			return fmt.Errorf("不支持的算法: %s", v)
+		}
+	}
+	if v := q.Get("digits"); v != "" {
+		digits, err := strconv.Atoi(v)
+		if err != nil || digits < 4 || digits > 10 {
+			return fmt.Errorf("位数必须在4到10之间")
+		}
+		k.Digits = digits
+	}
+	if v := q.Get("period"); v != "" {
+		period, err := strconv.Atoi(v)
+		if err != nil || period < 1 || period > 3600 {
+			return fmt.Errorf("周期必须在1到3600秒之间")
+		}
+		k.Period = period
+	}
+	if k.Type == OTPTypeHOTP {
+		if v := q.Get("counter"); v != "" {
+			counter, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("无效的计数器: %s", v)
+			}
+			k.Counter = counter
+		}
+	}
+	return nil
+}
+
+// normalizeOTPSecret 去掉空格、连字符和填充，校验Base32格式
+func normalizeOTPSecret(secret string) (string, error) {
+	secret = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(secret))
+	secret = strings.TrimRight(secret, "=")
+	if secret == "" {
+		return "", ErrInvalidOTP
+	}
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret); err != nil {
+		return "", ErrInvalidOTP
+	}
+	return secret, nil
+}
+
+// otpHash 返回算法对应的哈希函数，不支持的算法返回nil
+func otpHash(algorithm string) func() hash.Hash {
+	switch algorithm {
+	case "SHA1":
+		return sha1.New
+	case "SHA256":
+		return sha256.New
+	case "SHA512":
+		return sha512.New
+	}
+	return nil
+}
+
+// URI 生成规范的otpauth链接，HOTP链接中包含当前计数器
+func (k *OTPKey) URI() string {
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+
+	q := url.Values{}
+	q.Set("secret", k.Secret)
+	if k.Issuer != "" {
+		q.Set("issuer", k.Issuer)
+	}
+	if k.Steam {
+		q.Set("encoder", "steam")
+	} else {
+		q.Set("algorithm", k.Algorithm)
+		q.Set("digits", strconv.Itoa(k.Digits))
+	}
+	if k.Type == OTPTypeHOTP {
+		q.Set("counter", strconv.FormatUint(k.Counter, 10))
+	} else {
+		q.Set("period", strconv.Itoa(k.Period))
+	}
+
+	u := url.URL{Scheme: "otpauth", Host: k.Type, Path: "/" + label, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// Code 生成计数器对应的一次性密码（RFC 4226），Steam令牌使用其专用字母表
+func (k *OTPKey) Code(counter uint64) (string, error) {
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(k.Secret)
+	if err != nil {
+		return "", ErrInvalidOTP
+	}
+	newHash := otpHash(k.Algorithm)
+	if newHash == nil {
+		return "", fmt.Errorf("不支持的算法: %s", k.Algorithm)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(newHash, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	if k.Steam {
+		code := make([]byte, steamDigits)
+		for i := range code {
+			code[i] = steamAlphabet[value%uint32(len(steamAlphabet))]
+			value /= uint32(len(steamAlphabet))
+		}
+		return string(code), nil
+	}
+
+	mod := uint64(1)
+	for i := 0; i < k.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, uint64(value)%mod), nil
+}
+
+// TOTPCode 生成指定时间的TOTP（RFC 6238），同时返回当前周期剩余的秒数
+func (k *OTPKey) TOTPCode(t time.Time) (string, int, error) {
+	period := int64(k.Period)
+	unix := t.Unix()
+	code, err := k.Code(uint64(unix / period))
+	if err != nil {
+		return "", 0, err
+	}
+	return code, int(period - unix%period), nil
+}
+
+// 二维码图片的最大宽高，防止很小的压缩文件解码出巨大的图片
+const maxQRImageDimension = 4096
+
+// DecodeQRImage 识别PNG/JPEG/GIF图片中的二维码，返回其中的文本
+func DecodeQRImage(data []byte) (string, error) {
+	// 解码前先读取图片尺寸
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", errors.New("无法读取图片，仅支持PNG、JPEG和GIF格式")
+	}
+	if config.Width > maxQRImageDimension || config.Height > maxQRImageDimension {
+		return "", fmt.Errorf("图片尺寸过大，宽和高不能超过%d像素", maxQRImageDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", errors.New("无法读取图片，仅支持PNG、JPEG和GIF格式")
+	}
+
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", errors.New("无法读取图片，仅支持PNG、JPEG和GIF格式")
+	}
+
+	hints := map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+	}
+	result, err := qrcode.NewQRCodeReader().Decode(bmp, hints)
+	if err != nil {
+		return "", errors.New("图片中未识别到二维码")
+	}
+	return result.GetText(), nil
+}
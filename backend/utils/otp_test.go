@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base32"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+func base32Secret(s string) string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte(s))
+}
+
+// RFC 4226 附录D的测试向量
+func TestHOTPCode(t *testing.T) {
+	key, err := ParseOTP("otpauth://hotp/Example:alice?secret=" + base32Secret("12345678901234567890"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		got, err := key.Code(uint64(counter))
+		if err != nil || got != code {
+			t.Errorf("Code(%d) = %q, %v; want %q", counter, got, err, code)
+		}
+	}
+}
+
+// RFC 6238 附录B的测试向量
+func TestTOTPCode(t *testing.T) {
+	secrets := map[string]string{
+		"SHA1":   base32Secret("12345678901234567890"),
+		"SHA256": base32Secret("12345678901234567890123456789012"),
+		"SHA512": base32Secret("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	tests := []struct {
+		unix      int64
+		algorithm string
+		code      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{1234567890, "SHA1", "89005924"},
+		{1234567890, "SHA256", "91819424"},
+		{1234567890, "SHA512", "93441116"},
+		{2000000000, "SHA1", "69279037"},
+		{2000000000, "SHA256", "90698825"},
+		{2000000000, "SHA512", "38618901"},
+	}
+
+	for _, tt := range tests {
+		uri := "otpauth://totp/test?digits=8&algorithm=" + tt.algorithm + "&secret=" + secrets[tt.algorithm]
+		key, err := ParseOTP(uri)
+		if err != nil {
+			t.Fatalf("ParseOTP(%q): %v", uri, err)
+		}
+		code, remaining, err := key.TOTPCode(time.Unix(tt.unix, 0))
+		if err != nil || code != tt.code {
+			t.Errorf("%s at %d = %q, %v; want %q", tt.algorithm, tt.unix, code, err, tt.code)
+		}
+		if want := 30 - int(tt.unix%30); remaining != want {
+			t.Errorf("%s at %d remaining = %d, want %d", tt.algorithm, tt.unix, remaining, want)
+		}
+	}
+}
+
+func TestParseOTP(t *testing.T) {
+	tests := []struct {
+		value string
+		want  OTPKey
+	}{
+		{
+			value: "JBSW Y3DP-EHPK 3PXP",
+			want:  OTPKey{Type: OTPTypeTOTP, Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6, Period: 30},
+		},
+		{
+			value: "otpauth://totp/GitHub:alice@example.com?secret=jbswy3dpehpk3pxp&issuer=GitHub&algorithm=sha256&digits=8&period=60",
+			want:  OTPKey{Type: OTPTypeTOTP, Issuer: "GitHub", Account: "alice@example.com", Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA256", Digits: 8, Period: 60},
+		},
+		{
+			value: "otpauth://hotp/bob?secret=JBSWY3DPEHPK3PXP&counter=42",
+			want:  OTPKey{Type: OTPTypeHOTP, Account: "bob", Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6, Period: 30, Counter: 42},
+		},
+		{
+			value: "otpauth://totp/Steam:carol?secret=JBSWY3DPEHPK3PXP&encoder=steam&digits=8",
+			want:  OTPKey{Type: OTPTypeTOTP, Issuer: "Steam", Account: "carol", Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 5, Period: 30, Steam: true},
+		},
+		{
+			value: "steam://JBSWY3DPEHPK3PXP",
+			want:  OTPKey{Type: OTPTypeTOTP, Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 5, Period: 30, Steam: true},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseOTP(tt.value)
+		if err != nil {
+			t.Errorf("ParseOTP(%q): %v", tt.value, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("ParseOTP(%q) = %+v, want %+v", tt.value, *got, tt.want)
+		}
+	}
+}
+
+func TestParseOTPInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not base32!",
+		"otpauth://sms/alice?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/alice",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&digits=3",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&period=0",
+		"otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP&counter=-1",
+	}
+
+	for _, value := range tests {
+		if key, err := ParseOTP(value); err == nil {
+			t.Errorf("ParseOTP(%q) = %+v, want error", value, *key)
+		}
+	}
+}
+
+func TestOTPURIRoundTrip(t *testing.T) {
+	tests := []string{
+		"otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP&algorithm=SHA512&digits=8&period=45",
+		"otpauth://hotp/Bank:bob?secret=JBSWY3DPEHPK3PXP&counter=7",
+		"steam://JBSWY3DPEHPK3PXP",
+	}
+
+	for _, value := range tests {
+		key, err := ParseOTP(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := ParseOTP(key.URI())
+		if err != nil {
+			t.Errorf("ParseOTP(%q): %v", key.URI(), err)
+			continue
+		}
+		if *again != *key {
+			t.Errorf("round trip of %q = %+v, want %+v", value, *again, *key)
+		}
+	}
+}
+
+func TestDecodeQRImage(t *testing.T) {
+	const text = "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP"
+	matrix, err := qrcode.NewQRCodeWriter().Encode(text, gozxing.BarcodeFormat_QR_CODE, 200, 200, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, matrix); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DecodeQRImage(buf.Bytes())
+	if err != nil || got != text {
+		t.Errorf("DecodeQRImage() = %q, %v; want %q", got, err, text)
+	}
+
+	if _, err := DecodeQRImage([]byte("not an image")); err == nil {
+		t.Error("DecodeQRImage(garbage) succeeded")
+	}
+}
+
+func TestDecodeQRImageRejectsLargeImage(t *testing.T) {
+	// 单色大图压缩后只有几KB，解码后却要占用大量内存
+	img := image.NewGray(image.Rect(0, 0, maxQRImageDimension+1, 8))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := DecodeQRImage(buf.Bytes())
+	if err == nil || !strings.Contains(err.Error(), "尺寸") {
+		t.Errorf("DecodeQRImage(large) error = %v, want a size error", err)
+	}
+}
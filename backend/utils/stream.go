@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/007Secret/007Password/database"
+	"github.com/007Secret/007Password/middleware"
+)
+
+// 分块加密格式：
+//
+//	文件头: "007A" | 版本(1字节) | 随机nonce前缀(7字节)
+//	每个块: 结束标记(1字节) | 密文长度(4字节) | 密文(含16字节GCM标签)
+//
+// 每块的nonce为 前缀 | 块序号(4字节) | 结束标记，块被截断、重排或替换都会导致认证失败
+const (
+	streamMagic       = "007A"
+	streamVersion     = 1
+	streamChunkSize   = 64 * 1024
+	streamNoncePrefix = 7
+	streamHeaderSize  = len(streamMagic) + 1 + streamNoncePrefix
+)
+
+// ErrStreamCorrupted 加密文件已损坏或被篡改
+var ErrStreamCorrupted = errors.New("加密文件已损坏或被篡改")
+
+// vaultKey 获取加解密使用的密钥，与EncryptPassword使用同一个密钥
+func vaultKey() ([]byte, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库连接不可用")
+	}
+
+	encryptionKey, err := database.GetSetting("encryption_key")
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("获取加密密钥失败: %w", err)
+	}
+	if encryptionKey == "" {
+		encryptionKey = middleware.GetMasterPassword()
+		if encryptionKey == "" {
+			return nil, errors.New("无可用的加密密钥")
+		}
+	}
+
+	// 与EncryptPassword保持一致：首次加密时创建盐值
+	salt, err := passwordSalt()
+	if err != nil {
+		return nil, err
+	}
+
+	return deriveKey(encryptionKey, salt), nil
+}
+
+func newStreamAEAD() (cipher.AEAD, error) {
+	key, err := vaultKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func streamNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefix:], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptWriter 按块加密写入的数据，Close时写入最后一块
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	prefix  []byte
+	aad     []byte
+	buf     []byte
+	counter uint32
+	closed  bool
+}
+
+// NewEncryptWriter 创建分块加密写入器，aad用于将密文绑定到特定的存储对象，
+// 解密时必须提供相同的aad。调用方必须调用Close，否则文件不完整
+func NewEncryptWriter(w io.Writer, aad []byte) (io.WriteCloser, error) {
+	aead, err := newStreamAEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, streamNoncePrefix)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(streamMagic), streamVersion)
+	header = append(header, prefix...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		w:      w,
+		aead:   aead,
+		prefix: prefix,
+		aad:    aad,
+		buf:    make([]byte, 0, streamChunkSize),
+	}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("写入器已关闭")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// 缓冲区满且还有数据时才写出，保证最后一块在Close时带结束标记写出
+		if len(e.buf) == streamChunkSize {
+			if err := e.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):streamChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) flush(final bool) error {
+	sealed := e.aead.Seal(nil, streamNonce(e.prefix, e.counter, final), e.buf, e.aad)
+
+	record := make([]byte, 5, 5+len(sealed))
+	if final {
+		record[0] = 1
+	}
+	binary.BigEndian.PutUint32(record[1:], uint32(len(sealed)))
+	if _, err := e.w.Write(append(record, sealed...)); err != nil {
+		return err
+	}
+
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.flush(true)
+}
+
+// decryptReader 逐块解密读取
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	aad     []byte
+	plain   []byte
+	counter uint32
+	done    bool
+}
+
+// NewDecryptReader 创建分块解密读取器，数据被篡改或截断时Read返回ErrStreamCorrupted
+func NewDecryptReader(r io.Reader, aad []byte) (io.Reader, error) {
+	aead, err := newStreamAEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, ErrStreamCorrupted
+	}
+	if string(header[:len(streamMagic)]) != streamMagic || header[len(streamMagic)] != streamVersion {
+		return nil, ErrStreamCorrupted
+	}
+
+	return &decryptReader{
+		r:      br,
+		aead:   aead,
+		prefix: header[len(streamMagic)+1:],
+		aad:    aad,
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+func (d *decryptReader) next() error {
+	record := make([]byte, 5)
+	if _, err := io.ReadFull(d.r, record); err != nil {
+		// 没有读到结束块就到了文件末尾，说明文件被截断
+		return ErrStreamCorrupted
+	}
+
+	final := record[0] == 1
+	size := binary.BigEndian.Uint32(record[1:])
+	if size > streamChunkSize+uint32(d.aead.Overhead()) {
+		return ErrStreamCorrupted
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return ErrStreamCorrupted
+	}
+
+	plain, err := d.aead.Open(nil, streamNonce(d.prefix, d.counter, final), sealed, d.aad)
+	if err != nil {
+		return ErrStreamCorrupted
+	}
+
+	if final {
+		// 结束块之后不应再有数据
+		if _, err := d.r.Peek(1); err != io.EOF {
+			return ErrStreamCorrupted
+		}
+		d.done = true
+	}
+
+	d.counter++
+	d.plain = plain
+	return nil
+}
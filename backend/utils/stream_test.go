@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/007Secret/007Password/database"
+)
+
+// openTestVault 创建只有settings表的临时数据库并设置加密密钥，测试结束后恢复原来的全局连接
+func openTestVault(t *testing.T) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "vault.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		db.Close()
+		database.DB = previous
+	})
+
+	if _, err := db.Exec("CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT NOT NULL)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetSetting("encryption_key", "test-encryption-key"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func encryptStream(t *testing.T, plain, aad []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(sealed, aad []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(sealed), aad)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	openTestVault(t)
+
+	sizes := []int{0, 1, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 5}
+	for _, size := range sizes {
+		plain := make([]byte, size)
+		for i := range plain {
+			plain[i] = byte(i * 7)
+		}
+
+		sealed := encryptStream(t, plain, []byte("attachment:1"))
+		got, err := decryptStream(sealed, []byte("attachment:1"))
+		if err != nil {
+			t.Errorf("size %d: decrypt: %v", size, err)
+			continue
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("size %d: plaintext mismatch", size)
+		}
+	}
+}
+
+func TestStreamRejectsTampering(t *testing.T) {
+	openTestVault(t)
+
+	aad := []byte("attachment:1")
+	plain := bytes.Repeat([]byte("0123456789"), streamChunkSize/4)
+	sealed := encryptStream(t, plain, aad)
+	recordSize := 5 + streamChunkSize + 16
+	if len(sealed) <= streamHeaderSize+recordSize {
+		t.Fatalf("expected more than one chunk, got %d bytes", len(sealed))
+	}
+
+	flipped := append([]byte(nil), sealed...)
+	flipped[streamHeaderSize+10] ^= 1
+
+	// 交换前两块（最后一块较短，只交换完整的块）
+	swapped := append([]byte(nil), sealed[:streamHeaderSize]...)
+	first := sealed[streamHeaderSize : streamHeaderSize+recordSize]
+	second := sealed[streamHeaderSize+recordSize : streamHeaderSize+2*recordSize]
+	swapped = append(swapped, second...)
+	swapped = append(swapped, first...)
+	swapped = append(swapped, sealed[streamHeaderSize+2*recordSize:]...)
+
+	tests := []struct {
+		name   string
+		sealed []byte
+		aad    []byte
+	}{
+		{"wrong aad", sealed, []byte("attachment:2")},
+		{"flipped byte", flipped, aad},
+		{"truncated before final chunk", sealed[:streamHeaderSize+recordSize], aad},
+		{"truncated final chunk", sealed[:len(sealed)-1], aad},
+		{"trailing data", append(append([]byte(nil), sealed...), 0), aad},
+		{"reordered chunks", swapped, aad},
+		{"bad header", append([]byte("XXXX"), sealed[4:]...), aad},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decryptStream(tt.sealed, tt.aad); !errors.Is(err, ErrStreamCorrupted) {
+				t.Errorf("err = %v, want ErrStreamCorrupted", err)
+			}
+		})
+	}
+}
+
+func TestPasswordSaltKeptOnReadError(t *testing.T) {
+	openTestVault(t)
+
+	salt, err := passwordSalt()
+	if err != nil || salt == "" {
+		t.Fatalf("passwordSalt() = %q, %v; want a new salt", salt, err)
+	}
+	if again, err := passwordSalt(); err != nil || again != salt {
+		t.Fatalf("second passwordSalt() = %q, %v; want %q", again, err, salt)
+	}
+
+	// 读取出错（这里是表不可用）时不能创建新的盐值
+	if _, err := database.DB.Exec("ALTER TABLE settings RENAME TO settings_moved"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := passwordSalt(); err == nil {
+		t.Fatal("passwordSalt() succeeded while settings were unreadable")
+	}
+	if _, err := vaultKey(); err == nil {
+		t.Fatal("vaultKey() succeeded while settings were unreadable")
+	}
+	if _, err := database.DB.Exec("ALTER TABLE settings_moved RENAME TO settings"); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := passwordSalt(); again != salt {
+		t.Errorf("salt changed after a read error: %q, want %q", again, salt)
+	}
+}
+
+func TestEncryptPasswordRoundTrip(t *testing.T) {
+	openTestVault(t)
+
+	for _, plain := range []string{"p", "密码 with spaces", "********"} {
+		sealed, err := EncryptPassword(plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecryptPassword(sealed)
+		if err != nil || got != plain {
+			t.Errorf("DecryptPassword(EncryptPassword(%q)) = %q, %v", plain, got, err)
+		}
+	}
+}
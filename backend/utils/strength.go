@@ -0,0 +1,525 @@
+package utils
+
+import (
+	_ "embed"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// 内嵌的常见密码列表和字典词列表
+var (
+	//go:embed wordlists/common_passwords.txt
+	commonPasswordsData string
+
+	//go:embed wordlists/dictionary.txt
+	dictionaryData string
+)
+
+var (
+	commonPasswords map[string]struct{}
+	dictionaryWords []string
+	wordlistsOnce   sync.Once
+)
+
+// 默认的最低密码强度分数，可通过环境变量 MASTER_PASSWORD_MIN_SCORE 覆盖
+const defaultMinPasswordScore = 3
+
+// MaxStrengthInputLength 参与强度评估的最大字符数，更长的密码只评估前面的部分。
+// 弱模式的查找开销随长度快速增长，需要限制公开接口的输入
+const MaxStrengthInputLength = 256
+
+// 强度分数对应的熵阈值（单位：bit），依次对应分数 1、2、3、4
+var scoreThresholds = []float64{28, 40, 55, 70}
+
+// 键盘布局，用于识别相邻按键组成的序列
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+// 上档字符到基础按键的映射
+var shiftedKeys = map[rune]rune{
+	'~': '`', '!': '1', '@': '2', '#': '3', '$': '4', '%': '5', '^': '6',
+	'&': '7', '*': '8', '(': '9', ')': '0', '_': '-', '+': '=', '{': '[',
+	'}': ']', '|': '\\', ':': ';', '"': '\'', '<': ',', '>': '.', '?': '/',
+}
+
+// leet 替换字符到字母的映射
+var leetSubstitutions = map[rune]rune{
+	'@': 'a', '4': 'a', '3': 'e', '1': 'i', '!': 'i', '0': 'o',
+	'$': 's', '5': 's', '7': 't', '+': 't', '8': 'b', '9': 'g',
+}
+
+// StrengthFeedback 密码强度反馈项
+type StrengthFeedback struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordStrength 密码强度评估结果
+type PasswordStrength struct {
+	Score       int                `json:"score"`
+	Entropy     float64            `json:"entropy"`
+	MinScore    int                `json:"minScore"`
+	Acceptable  bool               `json:"acceptable"`
+	Warnings    []StrengthFeedback `json:"warnings"`
+	Suggestions []string           `json:"suggestions"`
+}
+
+// strengthMatch 密码中识别出的弱模式片段
+type strengthMatch struct {
+	start    int
+	end      int
+	entropy  float64
+	feedback StrengthFeedback
+}
+
+// MinPasswordScore 获取主密码要求的最低强度分数（0-4）
+func MinPasswordScore() int {
+	value := os.Getenv("MASTER_PASSWORD_MIN_SCORE")
+	if value == "" {
+		return defaultMinPasswordScore
+	}
+
+	score, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultMinPasswordScore
+	}
+	if score < 0 {
+		return 0
+	}
+	if score > 4 {
+		return 4
+	}
+	return score
+}
+
+// EntryStrengthScore 计算条目密码保存到数据库的强度分数，用于筛选弱密码。
+// 空密码和包含字段引用（{REF:...}）的密码无法评估，返回nil
+func EntryStrengthScore(password string) *int {
+	if password == "" || strings.Contains(strings.ToUpper(password), "{REF:") {
+		return nil
+	}
+	score := EvaluatePasswordStrength(password).Score
+	return &score
+}
+
+// EvaluatePasswordStrength 评估密码强度，返回分数、熵和可供前端展示的反馈。
+// 超过 MaxStrengthInputLength 个字符时只评估前面的部分，得到的分数不会高于实际强度
+func EvaluatePasswordStrength(password string) PasswordStrength {
+	wordlistsOnce.Do(loadWordlists)
+
+	if runes := []rune(password); len(runes) > MaxStrengthInputLength {
+		password = string(runes[:MaxStrengthInputLength])
+	}
+
+	result := PasswordStrength{
+		MinScore:    MinPasswordScore(),
+		Warnings:    []StrengthFeedback{},
+		Suggestions: []string{},
+	}
+
+	runes := []rune(password)
+	if len(runes) == 0 {
+		result.Warnings = append(result.Warnings, StrengthFeedback{Code: "empty", Message: "密码不能为空"})
+		result.Suggestions = append(result.Suggestions, "请输入主密码")
+		result.Acceptable = result.MinScore == 0
+		return result
+	}
+
+	// 常见密码直接判定为最低分
+	if isCommonPassword(password) {
+		result.Warnings = append(result.Warnings, StrengthFeedback{Code: "common_password", Message: "这是一个非常常见的密码，很容易被猜到"})
+		result.Suggestions = append(result.Suggestions, "不要使用常见密码或其简单变形")
+		result.Suggestions = append(result.Suggestions, "使用由多个随机单词组成的较长口令")
+		result.Acceptable = result.MinScore == 0
+		return result
+	}
+
+	lower := []rune(strings.ToLower(password))
+	normalized := make([]rune, len(lower))
+	for i, r := range lower {
+		if sub, ok := leetSubstitutions[r]; ok {
+			normalized[i] = sub
+		} else {
+			normalized[i] = r
+		}
+	}
+
+	bruteforce := math.Log2(float64(charsetSize(runes)))
+
+	var matches []strengthMatch
+	matches = append(matches, findDictionaryMatches(runes, lower, normalized)...)
+	matches = append(matches, findKeyboardMatches(lower)...)
+	matches = append(matches, findSequenceMatches(lower)...)
+	matches = append(matches, findRepeatMatches(lower, bruteforce)...)
+	matches = append(matches, findYearMatches(lower)...)
+
+	// 动态规划：选择覆盖整个密码且总熵最小的片段组合
+	n := len(runes)
+	best := make([]float64, n+1)
+	via := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		best[i] = best[i-1] + bruteforce
+		via[i] = -1
+		for j, m := range matches {
+			if m.end == i && best[m.start]+m.entropy < best[i] {
+				best[i] = best[m.start] + m.entropy
+				via[i] = j
+			}
+		}
+	}
+
+	// 回溯得到最终采用的弱模式，去重后生成警告
+	seen := make(map[string]bool)
+	for i := n; i > 0; {
+		if via[i] < 0 {
+			i--
+			continue
+		}
+		m := matches[via[i]]
+		if !seen[m.feedback.Code] {
+			seen[m.feedback.Code] = true
+			result.Warnings = append(result.Warnings, m.feedback)
+		}
+		i = m.start
+	}
+
+	result.Entropy = math.Round(best[n]*100) / 100
+	for _, threshold := range scoreThresholds {
+		if best[n] >= threshold {
+			result.Score++
+		}
+	}
+
+	if n < 8 {
+		result.Warnings = append(result.Warnings, StrengthFeedback{Code: "too_short", Message: "密码过短"})
+		if result.Score > 1 {
+			result.Score = 1
+		}
+	}
+
+	result.Suggestions = buildSuggestions(runes, seen, result.Score)
+	result.Acceptable = result.Score >= result.MinScore
+	return result
+}
+
+// loadWordlists 解析内嵌的词表
+func loadWordlists() {
+	commonPasswords = make(map[string]struct{})
+	for _, line := range parseWordlist(commonPasswordsData) {
+		commonPasswords[line] = struct{}{}
+	}
+
+	words := make(map[string]struct{})
+	for _, line := range parseWordlist(dictionaryData) {
+		words[line] = struct{}{}
+	}
+	// 常见密码中的纯字母项同样视为字典词
+	for word := range commonPasswords {
+		if len(word) >= 4 && strings.IndexFunc(word, func(r rune) bool { return !unicode.IsLetter(r) }) < 0 {
+			words[word] = struct{}{}
+		}
+	}
+	for word := range words {
+		if len(word) >= 3 {
+			dictionaryWords = append(dictionaryWords, word)
+		}
+	}
+}
+
+// parseWordlist 按行解析词表，忽略空行和#开头的注释
+func parseWordlist(data string) []string {
+	var result []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// isCommonPassword 判断密码（或去掉末尾数字符号、还原leet替换后）是否在常见密码列表中
+func isCommonPassword(password string) bool {
+	lower := strings.ToLower(password)
+	candidates := []string{lower}
+
+	trimmed := strings.TrimRightFunc(lower, func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+	if trimmed != "" && trimmed != lower {
+		candidates = append(candidates, trimmed)
+	}
+
+	unleet := strings.Map(func(r rune) rune {
+		if sub, ok := leetSubstitutions[r]; ok {
+			return sub
+		}
+		return r
+	}, lower)
+	if unleet != lower {
+		candidates = append(candidates, unleet)
+	}
+
+	for _, candidate := range candidates {
+		if _, ok := commonPasswords[candidate]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// charsetSize 根据密码中出现的字符类别估算字符集大小
+func charsetSize(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < 128:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	size := 0
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if symbol {
+		size += 33
+	}
+	if other {
+		size += 100
+	}
+	return size
+}
+
+// findDictionaryMatches 查找字典词（包括大小写变化和leet替换）
+func findDictionaryMatches(original, lower, normalized []rune) []strengthMatch {
+	var matches []strengthMatch
+	baseEntropy := math.Log2(float64(len(dictionaryWords)))
+
+	for _, source := range [][]rune{lower, normalized} {
+		text := string(source)
+		for _, word := range dictionaryWords {
+			offset := 0
+			for {
+				idx := strings.Index(text[offset:], word)
+				if idx < 0 {
+					break
+				}
+				byteStart := offset + idx
+				start := len([]rune(text[:byteStart]))
+				end := start + len([]rune(word))
+
+				entropy := baseEntropy
+				// 大小写变化和leet替换只增加少量熵
+				for _, r := range original[start:end] {
+					if unicode.IsUpper(r) {
+						entropy++
+						break
+					}
+				}
+				if string(lower[start:end]) != word {
+					entropy++
+				}
+
+				matches = append(matches, strengthMatch{
+					start:    start,
+					end:      end,
+					entropy:  entropy,
+					feedback: StrengthFeedback{Code: "dictionary_word", Message: "包含常见单词或名字"},
+				})
+				offset = byteStart + len(word)
+			}
+		}
+	}
+	return matches
+}
+
+// keyboardPosition 返回按键所在的行和列
+func keyboardPosition(r rune) (int, int, bool) {
+	if base, ok := shiftedKeys[r]; ok {
+		r = base
+	}
+	for row, keys := range keyboardRows {
+		if col := strings.IndexRune(keys, r); col >= 0 {
+			return row, col, true
+		}
+	}
+	return 0, 0, false
+}
+
+// keysAdjacent 判断两个按键在键盘上是否相邻
+func keysAdjacent(a, b rune) bool {
+	rowA, colA, okA := keyboardPosition(a)
+	rowB, colB, okB := keyboardPosition(b)
+	if !okA || !okB {
+		return false
+	}
+
+	switch rowB - rowA {
+	case 0:
+		return colB-colA == 1 || colA-colB == 1
+	case 1:
+		// 下一行的按键相对上一行向右错开半个键位
+		return colB == colA || colB == colA-1
+	case -1:
+		return colB == colA || colB == colA+1
+	}
+	return false
+}
+
+// findKeyboardMatches 查找键盘相邻按键序列，如 qwerty、1qaz2wsx
+func findKeyboardMatches(lower []rune) []strengthMatch {
+	var matches []strengthMatch
+	start := 0
+	for i := 1; i <= len(lower); i++ {
+		if i < len(lower) && keysAdjacent(lower[i-1], lower[i]) {
+			continue
+		}
+		if length := i - start; length >= 4 {
+			matches = append(matches, strengthMatch{
+				start:    start,
+				end:      i,
+				entropy:  math.Log2(47) + float64(length-1),
+				feedback: StrengthFeedback{Code: "keyboard_pattern", Message: "包含键盘上相邻按键组成的序列"},
+			})
+		}
+		start = i
+	}
+	return matches
+}
+
+// findSequenceMatches 查找连续字符序列，如 abcd、4321
+func findSequenceMatches(lower []rune) []strengthMatch {
+	var matches []strengthMatch
+	n := len(lower)
+	for i := 0; i+2 < n; {
+		delta := lower[i+1] - lower[i]
+		if (delta != 1 && delta != -1) || !unicode.IsLetter(lower[i]) && !unicode.IsDigit(lower[i]) {
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j+1 < n && lower[j+1]-lower[j] == delta {
+			j++
+		}
+
+		if length := j - i + 1; length >= 3 {
+			alphabet := 26.0
+			if unicode.IsDigit(lower[i]) {
+				alphabet = 10
+			}
+			entropy := math.Log2(alphabet) + math.Log2(float64(length))
+			if delta < 0 {
+				entropy++
+			}
+			matches = append(matches, strengthMatch{
+				start:    i,
+				end:      j + 1,
+				entropy:  entropy,
+				feedback: StrengthFeedback{Code: "sequence", Message: "包含连续的字母或数字序列"},
+			})
+		}
+		i = j
+	}
+	return matches
+}
+
+// findRepeatMatches 查找重复字符或重复片段，如 aaaa、abcabc
+func findRepeatMatches(lower []rune, bruteforce float64) []strengthMatch {
+	var matches []strengthMatch
+	n := len(lower)
+	for blockLen := 1; blockLen <= n/2; blockLen++ {
+		for start := 0; start+2*blockLen <= n; start++ {
+			end := start + blockLen
+			for end+blockLen <= n && string(lower[end:end+blockLen]) == string(lower[start:start+blockLen]) {
+				end += blockLen
+			}
+			repeats := (end - start) / blockLen
+			if repeats < 2 || (blockLen == 1 && repeats < 3) {
+				continue
+			}
+			matches = append(matches, strengthMatch{
+				start:    start,
+				end:      end,
+				entropy:  float64(blockLen)*bruteforce + math.Log2(float64(repeats)),
+				feedback: StrengthFeedback{Code: "repeat", Message: "包含重复的字符或片段"},
+			})
+		}
+	}
+	return matches
+}
+
+// findYearMatches 查找年份（19xx/20xx），常见于生日等个人信息
+func findYearMatches(lower []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := 0; i+4 <= len(lower); i++ {
+		s := string(lower[i : i+4])
+		if (strings.HasPrefix(s, "19") || strings.HasPrefix(s, "20")) && isAllDigits(s) {
+			matches = append(matches, strengthMatch{
+				start:    i,
+				end:      i + 4,
+				entropy:  math.Log2(200),
+				feedback: StrengthFeedback{Code: "year", Message: "包含年份，可能与个人信息相关"},
+			})
+		}
+	}
+	return matches
+}
+
+// isAllDigits 判断字符串是否全部为数字
+func isAllDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// buildSuggestions 根据识别出的弱模式给出改进建议
+func buildSuggestions(runes []rune, found map[string]bool, score int) []string {
+	suggestions := []string{}
+	if found["dictionary_word"] {
+		suggestions = append(suggestions, "避免使用常见单词、名字，大小写变化和字母替换作用有限")
+	}
+	if found["keyboard_pattern"] {
+		suggestions = append(suggestions, "避免使用键盘上相邻的按键序列")
+	}
+	if found["sequence"] || found["repeat"] {
+		suggestions = append(suggestions, "避免使用连续或重复的字符")
+	}
+	if found["year"] {
+		suggestions = append(suggestions, "避免使用生日、年份等与个人相关的信息")
+	}
+	if len(runes) < 12 {
+		suggestions = append(suggestions, "使用更长的密码，建议至少12个字符")
+	}
+	if score < 4 && charsetSize(runes) < 62 {
+		suggestions = append(suggestions, "混合使用大小写字母、数字和符号")
+	}
+	return suggestions
+}
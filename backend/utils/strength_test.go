@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluatePasswordStrengthWeak(t *testing.T) {
+	tests := []struct {
+		password string
+		warning  string
+	}{
+		{"password", "common_password"},
+		{"123456", "common_password"},
+		{"P@ssw0rd", "common_password"},
+		{"zxcvbnm,./asdf", "keyboard_pattern"},
+		{"1qaz2wsx3edc", "keyboard_pattern"},
+		{"aaaaaaaaaaaaaaaa", "repeat"},
+		{"abcdefghijklmn", "sequence"},
+		{"Ab1!", "too_short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			got := EvaluatePasswordStrength(tt.password)
+			if got.Score > 1 {
+				t.Errorf("Score = %d, want <= 1", got.Score)
+			}
+			if got.Acceptable {
+				t.Errorf("Acceptable = true, want false")
+			}
+			if !hasWarning(got, tt.warning) {
+				t.Errorf("Warnings = %v, want %q", got.Warnings, tt.warning)
+			}
+		})
+	}
+}
+
+func TestEvaluatePasswordStrengthStrong(t *testing.T) {
+	tests := []string{
+		"Xk9#mP2$vL7q-Long",
+		"tR7$kq!2Zp@9wLm#",
+		"v8#Qz!rT2m&Yp4^Lw9",
+	}
+
+	for _, password := range tests {
+		t.Run(password, func(t *testing.T) {
+			got := EvaluatePasswordStrength(password)
+			if got.Score < 4 {
+				t.Errorf("Score = %d (entropy %.2f, warnings %v), want 4", got.Score, got.Entropy, got.Warnings)
+			}
+			if !got.Acceptable {
+				t.Errorf("Acceptable = false, want true")
+			}
+		})
+	}
+}
+
+func TestEvaluatePasswordStrengthEmpty(t *testing.T) {
+	got := EvaluatePasswordStrength("")
+	if got.Score != 0 || !hasWarning(got, "empty") {
+		t.Errorf("got score %d warnings %v, want 0 and empty warning", got.Score, got.Warnings)
+	}
+}
+
+func TestEvaluatePasswordStrengthLongInput(t *testing.T) {
+	long := strings.Repeat("ab", 50000)
+	got := EvaluatePasswordStrength(long)
+	want := EvaluatePasswordStrength(long[:MaxStrengthInputLength])
+	if got.Score != want.Score || got.Entropy != want.Entropy {
+		t.Errorf("got score %d entropy %.2f, want the prefix result %d %.2f", got.Score, got.Entropy, want.Score, want.Entropy)
+	}
+}
+
+func TestEntryStrengthScore(t *testing.T) {
+	if got := EntryStrengthScore(""); got != nil {
+		t.Errorf("EntryStrengthScore(\"\") = %d, want nil", *got)
+	}
+	if got := EntryStrengthScore("{REF:P@I:3}"); got != nil {
+		t.Errorf("EntryStrengthScore(ref) = %d, want nil", *got)
+	}
+	if got := EntryStrengthScore("password"); got == nil || *got != 0 {
+		t.Errorf("EntryStrengthScore(\"password\") = %v, want 0", got)
+	}
+}
+
+func hasWarning(s PasswordStrength, code string) bool {
+	for _, w := range s.Warnings {
+		if w.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/007Secret/007Password/models"
+	"golang.org/x/net/publicsuffix"
+)
+
+// ParseLooseURL 解析网址，没有协议时按https处理，如 example.com/login
+func ParseLooseURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	return url.Parse(raw)
+}
+
+// BaseDomain 返回主机名的可注册域名，如 admin.example.co.uk 返回 example.co.uk。
+// IP地址、localhost等无法确定公共后缀的主机名原样返回
+func BaseDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" || net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// MatchURI 判断目标网址是否满足条目网址的匹配规则
+func MatchURI(pattern, mode, target string) bool {
+	switch mode {
+	case models.URIMatchDomain, models.URIMatchHost:
+		p, err := ParseLooseURL(pattern)
+		if err != nil {
+			return false
+		}
+		t, err := ParseLooseURL(target)
+		if err != nil {
+			return false
+		}
+		if mode == models.URIMatchHost {
+			return p.Host != "" && strings.EqualFold(p.Host, t.Host)
+		}
+		base := BaseDomain(p.Hostname())
+		return base != "" && base == BaseDomain(t.Hostname())
+	case models.URIMatchStartsWith:
+		return strings.HasPrefix(strings.ToLower(strings.TrimSpace(target)), strings.ToLower(strings.TrimSpace(pattern)))
+	case models.URIMatchRegex:
+		re, err := regexp.Compile(pattern)
+		return err == nil && re.MatchString(target)
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/007Secret/007Password/models"
+)
+
+func TestBaseDomain(t *testing.T) {
+	tests := []struct {
+		host, want string
+	}{
+		{"example.com", "example.com"},
+		{"admin.example.com", "example.com"},
+		{"admin.example.co.uk", "example.co.uk"},
+		{"a.b.example.com.cn", "example.com.cn"},
+		{"alice.github.io", "alice.github.io"}, // github.io 是公共后缀
+		{"WWW.Example.COM.", "example.com"},
+		{"localhost", "localhost"},
+		{"192.168.1.1", "192.168.1.1"},
+		{"::1", "::1"},
+		{"co.uk", "co.uk"}, // 公共后缀本身无法确定可注册域名
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := BaseDomain(tt.host); got != tt.want {
+			t.Errorf("BaseDomain(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestMatchURI(t *testing.T) {
+	tests := []struct {
+		pattern, mode, target string
+		want                  bool
+	}{
+		// 基础域名
+		{"https://app.example.com", models.URIMatchDomain, "https://admin.example.com/login", true},
+		{"example.com", models.URIMatchDomain, "http://www.example.com:8080", true},
+		{"https://example.co.uk", models.URIMatchDomain, "https://other.co.uk", false},
+		{"https://alice.github.io", models.URIMatchDomain, "https://bob.github.io", false},
+		{"http://localhost:3000", models.URIMatchDomain, "http://localhost:8080", true},
+		{"http://192.168.1.1", models.URIMatchDomain, "http://192.168.1.2", false},
+		{"http://192.168.1.1:8080", models.URIMatchDomain, "http://192.168.1.1/admin", true},
+		{"https://example.com", models.URIMatchDomain, "https://example.org", false},
+
+		// 主机名和端口
+		{"https://app.example.com", models.URIMatchHost, "https://APP.example.com/path", true},
+		{"https://app.example.com", models.URIMatchHost, "https://admin.example.com", false},
+		{"http://localhost:3000", models.URIMatchHost, "http://localhost:3000/login", true},
+		{"http://localhost:3000", models.URIMatchHost, "http://localhost:8080", false},
+		{"http://[::1]:8080", models.URIMatchHost, "http://[::1]:8080/", true},
+		{"http://10.0.0.1", models.URIMatchHost, "http://10.0.0.1:8080", false},
+
+		// 前缀，不区分大小写
+		{"https://example.com/admin", models.URIMatchStartsWith, "https://example.com/admin/users", true},
+		{"https://Example.com/Admin", models.URIMatchStartsWith, "HTTPS://EXAMPLE.COM/admin", true},
+		{" https://example.com/admin ", models.URIMatchStartsWith, "https://example.com/admin", true},
+		{"https://example.com/admin", models.URIMatchStartsWith, "https://example.com/", false},
+
+		// 正则表达式
+		{`^https://(www\.)?example\.com/`, models.URIMatchRegex, "https://www.example.com/login", true},
+		{`^https://(www\.)?example\.com/`, models.URIMatchRegex, "https://evil.com/?https://example.com/", false},
+		{`[`, models.URIMatchRegex, "https://example.com", false},
+
+		// 从不匹配及未知规则
+		{"https://example.com", models.URIMatchNever, "https://example.com", false},
+		{"https://example.com", "unknown", "https://example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchURI(tt.pattern, tt.mode, tt.target); got != tt.want {
+			t.Errorf("MatchURI(%q, %s, %q) = %v, want %v", tt.pattern, tt.mode, tt.target, got, tt.want)
+		}
+	}
+}
@@ -25,6 +25,11 @@ api.interceptors.request.use(
     if (token) {
       config.headers['Authorization'] = `Bearer ${token}`;
     }
+    // 二次验证令牌，用于查看受保护条目
+    const stepUpToken = sessionStorage.getItem('stepUpToken');
+    if (stepUpToken) {
+      config.headers['X-Step-Up-Token'] = stepUpToken;
+    }
     return config;
   },
   error => {
@@ -111,6 +116,29 @@ export const auth = {
     }
   },
   
+  // 重新输入主密码，获取查看受保护条目的短时令牌
+  stepUp: async (masterPassword) => {
+    try {
+      const response = await api.post('/auth/step-up', { masterPassword });
+      sessionStorage.setItem('stepUpToken', response.data.stepUpToken);
+      return response.data;
+    } catch (error) {
+      console.error('二次验证失败:', error);
+      throw error;
+    }
+  },
+
+  // 评估密码强度
+  checkPasswordStrength: async (password) => {
+    try {
+      const response = await api.post('/auth/password-strength', { password });
+      return response.data;
+    } catch (error) {
+      console.error('评估密码强度失败:', error);
+      throw error;
+    }
+  },
+  
   // 修改主密码
   changePassword: async (currentPassword, newPassword) => {
     try {
@@ -128,10 +156,10 @@ export const auth = {
 
 // 密码管理API
 export const passwords = {
-  // 获取所有密码
-  getAllPasswords: async () => {
+  // 获取所有密码，params 可包含 sort（recent/frequent/name/updated/created，前面加-为反向）和 favorite
+  getAllPasswords: async (params = {}) => {
     try {
-      const response = await api.get('/passwords');
+      const response = await api.get('/passwords', { params });
       console.log('获取密码列表API响应:', response);
       // 确保返回一个对象，其中包含data字段，如果data不是数组则转换为空数组
       return {
@@ -144,6 +172,22 @@ export const passwords = {
       return { data: [] };
     }
   },
+
+  // 分页获取密码列表，params 可包含 limit、cursor、fields（逗号分隔）以及排序和筛选参数，
+  // 返回当前页的条目、总数和下一页的游标（没有下一页时为null）
+  getPasswordsPage: async (params = {}) => {
+    try {
+      const response = await api.get('/passwords', { params });
+      return {
+        items: Array.isArray(response.data) ? response.data : [],
+        total: parseInt(response.headers['x-total-count'], 10) || 0,
+        nextCursor: response.headers['x-next-cursor'] || null
+      };
+    } catch (error) {
+      console.error('分页获取密码列表失败:', error);
+      throw error;
+    }
+  },
   
   // 获取单个密码
   getPassword: async (id) => {
@@ -156,6 +200,48 @@ export const passwords = {
     }
   },
   
+  // 解密并获取单个字段（默认为密码），每次调用都会记录审计日志
+  revealPassword: async (id, field = 'password') => {
+    try {
+      const response = await api.post(`/passwords/${id}/reveal`, { field });
+      return response.data.value;
+    } catch (error) {
+      console.error(`获取密码ID=${id}明文失败:`, error);
+      throw error;
+    }
+  },
+
+  // 收藏或取消收藏
+  setFavorite: async (id, favorite) => {
+    try {
+      const response = await api.put(`/passwords/${id}/favorite`, { favorite });
+      return response.data;
+    } catch (error) {
+      console.error(`设置密码ID=${id}收藏失败:`, error);
+      throw error;
+    }
+  },
+
+  // 记录条目使用（如复制），查看密码时服务端会自动记录
+  recordUse: async (id) => {
+    try {
+      await api.post(`/passwords/${id}/use`);
+    } catch (error) {
+      console.error(`记录密码ID=${id}使用失败:`, error);
+    }
+  },
+
+  // 获取引用了该条目字段的条目
+  getDependents: async (id) => {
+    try {
+      const response = await api.get(`/passwords/${id}/dependents`);
+      return response.data;
+    } catch (error) {
+      console.error(`获取引用密码ID=${id}的条目失败:`, error);
+      throw error;
+    }
+  },
+
   // 创建新密码
   createPassword: async (passwordData) => {
     try {
@@ -168,9 +254,12 @@ export const passwords = {
   },
   
   // 更新密码
-  updatePassword: async (id, passwordData) => {
+  // revision为编辑前获取的条目修订号，服务端据此拒绝覆盖他人的修改（412）
+  updatePassword: async (id, passwordData, revision) => {
     try {
-      const response = await api.put(`/passwords/${id}`, passwordData);
+      const response = await api.put(`/passwords/${id}`, passwordData, {
+        headers: { 'If-Match': revision ? `"${revision}"` : '*' }
+      });
       return response.data;
     } catch (error) {
       console.error(`更新密码ID=${id}失败:`, error);
@@ -178,10 +267,40 @@ export const passwords = {
     }
   },
   
+  // 只修改提交的字段（JSON Merge Patch），值为null表示清空该字段
+  patchPassword: async (id, patch, revision) => {
+    try {
+      const response = await api.patch(`/passwords/${id}`, patch, {
+        headers: {
+          'Content-Type': 'application/merge-patch+json',
+          'If-Match': revision ? `"${revision}"` : '*'
+        }
+      });
+      return response.data;
+    } catch (error) {
+      console.error(`修改密码ID=${id}失败:`, error);
+      throw error;
+    }
+  },
+
+  // 批量执行新建、修改、删除、标签和移动操作，mode为 atomic（默认）或 bestEffort；
+  // 除create外每项操作都需要带 revision（条目的修订号，'*' 表示不检查）
+  batch: async (operations, mode = 'atomic') => {
+    try {
+      const response = await api.post('/passwords/batch', { mode, operations });
+      return response.data;
+    } catch (error) {
+      console.error('批量操作失败:', error);
+      throw error;
+    }
+  },
+
   // 删除密码
-  deletePassword: async (id) => {
+  deletePassword: async (id, revision) => {
     try {
-      const response = await api.delete(`/passwords/${id}`);
+      const response = await api.delete(`/passwords/${id}`, {
+        headers: { 'If-Match': revision ? `"${revision}"` : '*' }
+      });
       return response.data;
     } catch (error) {
       console.error(`删除密码ID=${id}失败:`, error);
@@ -189,10 +308,11 @@ export const passwords = {
     }
   },
   
-  // 搜索密码
-  searchPasswords: async (query) => {
+  // 搜索密码，query 支持 site:、tag:、weak:、updated:<2024-01-01 等筛选条件，
+  // 结果按相关度排序，snippet中匹配的词用<mark>标记
+  searchPasswords: async (query, params = {}) => {
     try {
-      const response = await api.get(`/passwords/search?q=${query}`);
+      const response = await api.get('/passwords/search', { params: { ...params, q: query } });
       return response.data;
     } catch (error) {
       console.error('搜索密码失败:', error);
@@ -218,7 +338,7 @@ export const passwords = {
           escapeCsvField(pwd.password || ''),
           escapeCsvField(pwd.email || ''),
           escapeCsvField(pwd.phone || ''),
-          escapeCsvField(pwd.website || ''),
+          escapeCsvField(pwd.uris && pwd.uris.length ? pwd.uris[0].uri : ''),
           escapeCsvField(pwd.notes || '')
         ];
         csvContent += row.join(',') + '\n';
@@ -241,7 +361,155 @@ function escapeCsvField(field) {
   return field;
 }
 
+// 身份提供方（授权登录方式）
+export const identityProviders = {
+  // 获取所有身份提供方
+  getAll: async () => {
+    try {
+      const response = await api.get('/identity-providers');
+      return Array.isArray(response.data) ? response.data : [];
+    } catch (error) {
+      console.error('获取身份提供方失败:', error);
+      return [];
+    }
+  },
+
+  // 创建身份提供方
+  create: async (name) => {
+    try {
+      const response = await api.post('/identity-providers', { name });
+      return response.data;
+    } catch (error) {
+      console.error('创建身份提供方失败:', error);
+      throw error;
+    }
+  }
+};
+
+// 条目模板
+export const templates = {
+  // 获取所有模板
+  getAll: async () => {
+    try {
+      const response = await api.get('/templates');
+      return Array.isArray(response.data) ? response.data : [];
+    } catch (error) {
+      console.error('获取模板失败:', error);
+      return [];
+    }
+  },
+
+  // 按模板创建条目，values 以模板字段名称为键，未填写的字段使用默认值或自动生成
+  createEntry: async (id, entry) => {
+    try {
+      const response = await api.post(`/templates/${id}/entries`, entry);
+      return response.data;
+    } catch (error) {
+      console.error(`按模板ID=${id}创建条目失败:`, error);
+      throw error;
+    }
+  }
+};
+
+// 到期提醒
+export const notifications = {
+  // 获取提醒列表及未读数量
+  getAll: async () => {
+    try {
+      const response = await api.get('/notifications');
+      return response.data;
+    } catch (error) {
+      console.error('获取提醒失败:', error);
+      return { notifications: [], unread: 0 };
+    }
+  },
+
+  // 将提醒标记为已读
+  markRead: async (id) => {
+    try {
+      const response = await api.put(`/notifications/${id}/read`);
+      return response.data;
+    } catch (error) {
+      console.error(`标记提醒ID=${id}已读失败:`, error);
+      throw error;
+    }
+  },
+
+  // 将所有提醒标记为已读
+  markAllRead: async () => {
+    try {
+      const response = await api.put('/notifications/read-all');
+      return response.data;
+    } catch (error) {
+      console.error('标记全部提醒已读失败:', error);
+      throw error;
+    }
+  }
+};
+
+// 智能文件夹：保存的结构化搜索语句
+export const smartFolders = {
+  // 获取所有智能文件夹及匹配的条目数量
+  getAll: async () => {
+    try {
+      const response = await api.get('/smart-folders');
+      return Array.isArray(response.data) ? response.data : [];
+    } catch (error) {
+      console.error('获取智能文件夹失败:', error);
+      return [];
+    }
+  },
+
+  // 将搜索语句保存为智能文件夹
+  create: async (name, query) => {
+    try {
+      const response = await api.post('/smart-folders', { name, query });
+      return response.data;
+    } catch (error) {
+      console.error('创建智能文件夹失败:', error);
+      throw error;
+    }
+  },
+
+  // 修改智能文件夹的名称和搜索语句
+  update: async (id, name, query) => {
+    try {
+      const response = await api.put(`/smart-folders/${id}`, { name, query });
+      return response.data;
+    } catch (error) {
+      console.error(`修改智能文件夹ID=${id}失败:`, error);
+      throw error;
+    }
+  },
+
+  // 删除智能文件夹
+  delete: async (id) => {
+    try {
+      const response = await api.delete(`/smart-folders/${id}`);
+      return response.data;
+    } catch (error) {
+      console.error(`删除智能文件夹ID=${id}失败:`, error);
+      throw error;
+    }
+  },
+
+  // 获取智能文件夹中的条目，params 与 getPasswordsPage 相同，可包含 limit、cursor、sort 和 fields
+  getPasswords: async (id, params = {}) => {
+    try {
+      const response = await api.get(`/smart-folders/${id}/passwords`, { params });
+      return response.data;
+    } catch (error) {
+      console.error(`获取智能文件夹ID=${id}的条目失败:`, error);
+      throw error;
+    }
+  }
+};
+
 export default {
   auth,
-  passwords
+  passwords,
+  identityProviders,
+  templates,
+  notifications,
+  smartFolders
 }; 
\ No newline at end of file